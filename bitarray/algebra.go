@@ -0,0 +1,162 @@
+package bitarray
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+func and(a, b uint64) uint64 {
+	return a & b
+}
+
+func or(a, b uint64) uint64 {
+	return a | b
+}
+
+func xor(a, b uint64) uint64 {
+	return a ^ b
+}
+
+func andNot(a, b uint64) uint64 {
+	return a &^ b
+}
+
+// And keep only the bits also set in other
+func (ab *SyncBitArray) And(other *SyncBitArray) {
+	ab.combine(other, and)
+}
+
+// Or set the bits set in other
+func (ab *SyncBitArray) Or(other *SyncBitArray) {
+	ab.combine(other, or)
+}
+
+// Xor flip the bits set in other
+func (ab *SyncBitArray) Xor(other *SyncBitArray) {
+	ab.combine(other, xor)
+}
+
+// AndNot clear the bits set in other
+func (ab *SyncBitArray) AndNot(other *SyncBitArray) {
+	ab.combine(other, andNot)
+}
+
+// Not flip every bit
+func (ab *SyncBitArray) Not() {
+	ab.gate.enter()
+	defer ab.gate.exit()
+	for i := range ab.data {
+		mask := ab.wordMask(i)
+		ab.updateWord(i, func(old uint64) uint64 {
+			return ^old & mask
+		})
+	}
+}
+
+// And new array of the bits set in both a and b
+func And(a, b *SyncBitArray) *SyncBitArray {
+	return combineOf(a, b, and)
+}
+
+// Or new array of the bits set in a or b
+func Or(a, b *SyncBitArray) *SyncBitArray {
+	return combineOf(a, b, or)
+}
+
+// Xor new array of the bits set in exactly one of a and b
+func Xor(a, b *SyncBitArray) *SyncBitArray {
+	return combineOf(a, b, xor)
+}
+
+// AndNot new array of the bits set in a but not in b
+func AndNot(a, b *SyncBitArray) *SyncBitArray {
+	return combineOf(a, b, andNot)
+}
+
+// Not new array with every bit of a flipped
+func Not(a *SyncBitArray) *SyncBitArray {
+	ret := New(a.len)
+	var cnt int
+	for i := range a.data {
+		v := ^a.data[i].Load() & a.wordMask(i)
+		ret.data[i].Store(v)
+		cnt += bits.OnesCount64(v)
+	}
+	ret.bitCnt.Add(int64(cnt))
+	return ret
+}
+
+// IntersectionCount count of bits set in both arrays
+func (ab *SyncBitArray) IntersectionCount(other *SyncBitArray) int {
+	ab.checkLen(other)
+	var cnt int
+	for i := range ab.data {
+		cnt += bits.OnesCount64(ab.data[i].Load() & other.data[i].Load() & ab.wordMask(i))
+	}
+	return cnt
+}
+
+// UnionCount count of bits set in any of the arrays
+func (ab *SyncBitArray) UnionCount(other *SyncBitArray) int {
+	ab.checkLen(other)
+	var cnt int
+	for i := range ab.data {
+		cnt += bits.OnesCount64((ab.data[i].Load() | other.data[i].Load()) & ab.wordMask(i))
+	}
+	return cnt
+}
+
+func (ab *SyncBitArray) checkLen(other *SyncBitArray) {
+	if ab.len != other.len {
+		panic(fmt.Sprintf("length mismatch %d != %d", ab.len, other.len))
+	}
+}
+
+// wordMask bits of word i within the array length
+func (ab *SyncBitArray) wordMask(i int) uint64 {
+	if i == len(ab.data)-1 && ab.len%bitPerUnit != 0 {
+		return ^uint64(0) >> (bitPerUnit - ab.len%bitPerUnit)
+	}
+	return ^uint64(0)
+}
+
+// updateWord CAS word i to fn(old) and account the changed bits, return the old value
+func (ab *SyncBitArray) updateWord(i int, fn func(old uint64) uint64) uint64 {
+	for {
+		old := ab.data[i].Load()
+		neu := fn(old)
+		if old == neu {
+			return old
+		}
+		if ab.data[i].CompareAndSwap(old, neu) {
+			ab.bitCnt.Add(int64(bits.OnesCount64(neu) - bits.OnesCount64(old)))
+			return old
+		}
+	}
+}
+
+func (ab *SyncBitArray) combine(other *SyncBitArray, op func(a, b uint64) uint64) {
+	ab.checkLen(other)
+	ab.gate.enter()
+	defer ab.gate.exit()
+	for i := range ab.data {
+		v := other.data[i].Load()
+		mask := ab.wordMask(i)
+		ab.updateWord(i, func(old uint64) uint64 {
+			return op(old, v) & mask
+		})
+	}
+}
+
+func combineOf(a, b *SyncBitArray, op func(a, b uint64) uint64) *SyncBitArray {
+	a.checkLen(b)
+	ret := New(a.len)
+	var cnt int
+	for i := range a.data {
+		v := op(a.data[i].Load(), b.data[i].Load()) & a.wordMask(i)
+		ret.data[i].Store(v)
+		cnt += bits.OnesCount64(v)
+	}
+	ret.bitCnt.Add(int64(cnt))
+	return ret
+}
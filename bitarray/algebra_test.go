@@ -0,0 +1,73 @@
+package bitarray
+
+import (
+	"github.com/stretchr/testify/assert"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func newWith(length int, idx ...int) *SyncBitArray {
+	ba := New(length)
+	for _, i := range idx {
+		ba.Set(i)
+	}
+	return ba
+}
+
+func TestAlgebra(t *testing.T) {
+	a := newWith(70, 1, 2, 64, 69)
+	b := newWith(70, 2, 3, 64, 68)
+	assert.Equal(t, []int{2, 64}, slices.Collect(And(a, b).IterSet()))
+	assert.Equal(t, []int{1, 2, 3, 64, 68, 69}, slices.Collect(Or(a, b).IterSet()))
+	assert.Equal(t, []int{1, 3, 68, 69}, slices.Collect(Xor(a, b).IterSet()))
+	assert.Equal(t, []int{1, 69}, slices.Collect(AndNot(a, b).IterSet()))
+	n := Not(a)
+	assert.Equal(t, 66, n.BitCnt())
+	assert.False(t, n.Get(1))
+	assert.True(t, n.Get(0))
+	assert.Equal(t, 2, a.IntersectionCount(b))
+	assert.Equal(t, 6, a.UnionCount(b))
+	assert.Equal(t, 4, a.BitCnt())
+
+	c := newWith(70, 1, 2, 64, 69)
+	c.Or(b)
+	assert.Equal(t, 6, c.BitCnt())
+	c.AndNot(a)
+	assert.Equal(t, []int{3, 68}, slices.Collect(c.IterSet()))
+	assert.Equal(t, 2, c.BitCnt())
+	c.Xor(b)
+	assert.Equal(t, []int{2, 64}, slices.Collect(c.IterSet()))
+	c.And(a)
+	assert.Equal(t, 2, c.BitCnt())
+	c.Not()
+	assert.Equal(t, 68, c.BitCnt())
+	assert.Equal(t, -1, c.NextSet(70))
+	assert.Panics(t, func() {
+		c.And(New(71))
+	})
+}
+
+func TestAlgebraConcurrent(t *testing.T) {
+	length := 4096
+	a := New(length)
+	odd := New(length)
+	for i := 1; i < length; i += 2 {
+		odd.Set(i)
+	}
+	var wg sync.WaitGroup
+	for w := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := w; i < length; i += 4 {
+				a.Set(i)
+			}
+			a.AndNot(odd)
+		}()
+	}
+	wg.Wait()
+	a.AndNot(odd)
+	assert.Equal(t, length/2, a.BitCnt())
+	assert.Equal(t, length/2, len(slices.Collect(a.IterSet())))
+}
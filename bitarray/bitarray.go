@@ -16,6 +16,7 @@ type SyncBitArray struct {
 	data   []atomic.Uint64
 	bitCnt *i64adder.Adder
 	len    int
+	gate   writeGate
 }
 
 func New(size int) *SyncBitArray {
@@ -49,6 +50,9 @@ func (ab *SyncBitArray) Set(index int) bool {
 		if oldValue == newValue {
 			return false
 		}
+		if ab.gate.paused() {
+			continue
+		}
 		if ab.data[aIdx].CompareAndSwap(oldValue, newValue) {
 			ab.bitCnt.Add(1)
 			return true
@@ -95,6 +99,9 @@ func (ab *SyncBitArray) Unset(index int) bool {
 		if oldValue == newValue {
 			return false
 		}
+		if ab.gate.paused() {
+			continue
+		}
 		if ab.data[aIdx].CompareAndSwap(oldValue, newValue) {
 			ab.bitCnt.Add(-1)
 			return true
@@ -104,6 +111,8 @@ func (ab *SyncBitArray) Unset(index int) bool {
 
 // Clear all bits in the array
 func (ab *SyncBitArray) Clear() {
+	ab.gate.enter()
+	defer ab.gate.exit()
 	for i := range ab.data {
 		oldValue := ab.data[i].Swap(0)
 		ab.bitCnt.Add(-int64(bits.OnesCount64(oldValue)))
@@ -121,6 +130,9 @@ func (ab *SyncBitArray) PutUint64(idx int, value uint64) {
 		if old == neu {
 			break
 		}
+		if ab.gate.paused() {
+			continue
+		}
 		if ab.data[idx>>uint64Bit].CompareAndSwap(old, neu) {
 			update = true
 			break
@@ -155,9 +167,12 @@ func NewFrom(data []uint64) *SyncBitArray {
 }
 
 func (ab *SyncBitArray) String() string {
+	return formatWords(ab.Uint64Array())
+}
+
+func formatWords(u64a []uint64) string {
 	sb := strings.Builder{}
 	sb.WriteString("[")
-	u64a := ab.Uint64Array()
 	for i, v := range u64a {
 		binaryStr := fmt.Sprintf("%064b", v)
 		for j, ch := range reverse(binaryStr) {
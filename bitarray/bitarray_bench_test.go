@@ -0,0 +1,64 @@
+package bitarray
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const benchSize = 1 << 20
+
+func BenchmarkSyncBitArray_Set(b *testing.B) {
+	ab := New(benchSize)
+	for i := 0; i < b.N; i++ {
+		ab.Set(i & (benchSize - 1))
+	}
+}
+
+func BenchmarkSyncBitArray_SetUnset(b *testing.B) {
+	ab := New(benchSize)
+	for i := 0; i < b.N; i++ {
+		ab.Set(i & (benchSize - 1))
+		ab.Unset(i & (benchSize - 1))
+	}
+}
+
+func BenchmarkSyncBitArray_SetParallel(b *testing.B) {
+	ab := New(benchSize)
+	var seq atomic.Int64
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(seq.Add(1)) << 12
+		for pb.Next() {
+			ab.Set(i & (benchSize - 1))
+			i++
+		}
+	})
+}
+
+func BenchmarkSyncBitArray_SetDuringSnapshot(b *testing.B) {
+	ab := New(benchSize)
+	var stop atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for !stop.Load() {
+			ab.Snapshot()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ab.Set(i & (benchSize - 1))
+		ab.Unset(i & (benchSize - 1))
+	}
+	b.StopTimer()
+	stop.Store(true)
+	<-done
+}
+
+func BenchmarkBitArray_Set(b *testing.B) {
+	ba := NewBitArray(benchSize)
+	for i := 0; i < b.N; i++ {
+		ba.Set(i & (benchSize - 1))
+	}
+}
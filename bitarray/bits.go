@@ -0,0 +1,130 @@
+package bitarray
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+)
+
+// Bits common operations of the bit arrays
+type Bits interface {
+	Len() int
+	Set(index int) bool
+	Get(index int) bool
+	Unset(index int) bool
+	Clear()
+	BitCnt() int
+	Iter() iter.Seq2[int, bool]
+}
+
+var _ Bits = (*SyncBitArray)(nil)
+var _ Bits = (*BitArray)(nil)
+var _ Bits = (*GrowableBitArray)(nil)
+
+// BitArray plain bit array without atomics, for use by a single goroutine
+type BitArray struct {
+	data   []uint64
+	bitCnt int
+	len    int
+}
+
+func NewBitArray(size int) *BitArray {
+	if size <= 0 {
+		panic("size must be greater than zero")
+	}
+	return &BitArray{data: make([]uint64, (size+bitPerUnit-1)/bitPerUnit), len: size}
+}
+
+// NewBitArrayFrom new bitarray from exist array, data is copied
+func NewBitArrayFrom(data []uint64) *BitArray {
+	ba := &BitArray{data: make([]uint64, len(data)), len: len(data) * bitPerUnit}
+	copy(ba.data, data)
+	for _, v := range data {
+		ba.bitCnt += bits.OnesCount64(v)
+	}
+	return ba
+}
+
+// Len array length
+func (ba *BitArray) Len() int {
+	return ba.len
+}
+
+// Set given index to 1b
+func (ba *BitArray) Set(index int) bool {
+	ba.check(index)
+	w := &ba.data[index>>uint64Bit]
+	mask := uint64(1) << (index % bitPerUnit)
+	if *w&mask != 0 {
+		return false
+	}
+	*w |= mask
+	ba.bitCnt++
+	return true
+}
+
+// Get return true if given index in bitarray is 1b
+func (ba *BitArray) Get(index int) bool {
+	ba.check(index)
+	return ba.data[index>>uint64Bit]&(1<<(index%bitPerUnit)) != 0
+}
+
+// Unset given index to 0b
+func (ba *BitArray) Unset(index int) bool {
+	ba.check(index)
+	w := &ba.data[index>>uint64Bit]
+	mask := uint64(1) << (index % bitPerUnit)
+	if *w&mask == 0 {
+		return false
+	}
+	*w &^= mask
+	ba.bitCnt--
+	return true
+}
+
+// Clear all bits in the array
+func (ba *BitArray) Clear() {
+	clear(ba.data)
+	ba.bitCnt = 0
+}
+
+// BitCnt bit 1 count
+func (ba *BitArray) BitCnt() int {
+	return ba.bitCnt
+}
+
+// Iter [index,set flag]
+func (ba *BitArray) Iter() iter.Seq2[int, bool] {
+	return func(yield func(int, bool) bool) {
+		for i := range ba.len {
+			if !yield(i, ba.Get(i)) {
+				break
+			}
+		}
+	}
+}
+
+// PutUint64 put all value bit into uint64 idx
+func (ba *BitArray) PutUint64(idx int, value uint64) {
+	w := &ba.data[idx>>uint64Bit]
+	neu := *w | value
+	ba.bitCnt += bits.OnesCount64(neu) - bits.OnesCount64(*w)
+	*w = neu
+}
+
+// Uint64Array copy of the words
+func (ba *BitArray) Uint64Array() []uint64 {
+	ret := make([]uint64, len(ba.data))
+	copy(ret, ba.data)
+	return ret
+}
+
+func (ba *BitArray) String() string {
+	return formatWords(ba.data)
+}
+
+func (ba *BitArray) check(index int) {
+	if index < 0 || index >= ba.len {
+		panic(fmt.Sprintf("index %d out of range %d", index, ba.len))
+	}
+}
@@ -0,0 +1,48 @@
+package bitarray
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBitArray(t *testing.T) {
+	ba := NewBitArray(100)
+	assert.True(t, ba.Set(3))
+	assert.False(t, ba.Set(3))
+	assert.True(t, ba.Set(99))
+	assert.True(t, ba.Get(99))
+	assert.False(t, ba.Get(98))
+	assert.Equal(t, 2, ba.BitCnt())
+	assert.True(t, ba.Unset(3))
+	assert.False(t, ba.Unset(3))
+	ba.PutUint64(64, 7)
+	assert.Equal(t, 4, ba.BitCnt())
+	assert.Equal(t, NewFrom(ba.Uint64Array()).String(), ba.String())
+	ba.Clear()
+	assert.Equal(t, 0, ba.BitCnt())
+	assert.Panics(t, func() {
+		ba.Get(100)
+	})
+	from := NewBitArrayFrom([]uint64{3, 1})
+	assert.Equal(t, 128, from.Len())
+	assert.Equal(t, 3, from.BitCnt())
+}
+
+func TestBitsInterface(t *testing.T) {
+	for _, b := range []Bits{New(130), NewBitArray(130), NewGrowable()} {
+		b.Set(1)
+		b.Set(129)
+		b.Unset(1)
+		assert.Equal(t, 1, b.BitCnt())
+		assert.Equal(t, 130, b.Len())
+		var set []int
+		for i, v := range b.Iter() {
+			if v {
+				set = append(set, i)
+			}
+		}
+		assert.Equal(t, []int{129}, set)
+		b.Clear()
+		assert.Equal(t, 0, b.BitCnt())
+	}
+}
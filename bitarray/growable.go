@@ -0,0 +1,240 @@
+package bitarray
+
+import (
+	"fmt"
+	"gotools/i64adder"
+	"iter"
+	"math"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+const chunkWordBit = 10
+const chunkWords = 1 << chunkWordBit
+const chunkBit = chunkWordBit + uint64Bit
+
+// dirBits chunk index bits resolved by one directory level
+const dirBits = 8
+const dirFanout = 1 << dirBits
+
+type chunk [chunkWords]atomic.Uint64
+
+// dirNode radix directory node, the last level points to chunks and the others to nodes
+type dirNode struct {
+	kids   []atomic.Pointer[dirNode]
+	chunks []atomic.Pointer[chunk]
+}
+
+func newDirNode(leaf bool) *dirNode {
+	if leaf {
+		return &dirNode{chunks: make([]atomic.Pointer[chunk], dirFanout)}
+	}
+	return &dirNode{kids: make([]atomic.Pointer[dirNode], dirFanout)}
+}
+
+// directory root of height levels, covering chunk indexes below 1<<(height*dirBits)
+type directory struct {
+	height int
+	root   *dirNode
+}
+
+// GrowableBitArray bit array without upper bound, words live in fixed size chunks allocated on the first Set
+// into them. Chunks are found through a radix directory whose height grows with the highest index, so memory
+// follows the set bits rather than the largest index. Growing swaps in a new root holding the old one behind
+// an atomic pointer, nodes and chunks never move so lock free CAS on words stays valid while it grows.
+type GrowableBitArray struct {
+	dir    atomic.Pointer[directory]
+	bitCnt *i64adder.Adder
+	len    atomic.Int64
+	grow   sync.Mutex
+}
+
+func NewGrowable() *GrowableBitArray {
+	ga := &GrowableBitArray{bitCnt: i64adder.New()}
+	ga.dir.Store(&directory{height: 1, root: newDirNode(true)})
+	return ga
+}
+
+// Len one past the highest index ever set
+func (ga *GrowableBitArray) Len() int {
+	return int(ga.len.Load())
+}
+
+// Set given index to 1b, growing the array if needed
+func (ga *GrowableBitArray) Set(index int) bool {
+	checkIndex(index)
+	c := ga.chunkOf(index)
+	if c == nil {
+		c = ga.allocChunk(index)
+	}
+	for {
+		l := ga.len.Load()
+		if int64(index) < l || ga.len.CompareAndSwap(l, int64(index)+1) {
+			break
+		}
+	}
+	w := &c[(index>>uint64Bit)&(chunkWords-1)]
+	mask := uint64(1) << (index % bitPerUnit)
+	for {
+		old := w.Load()
+		if old&mask != 0 {
+			return false
+		}
+		if w.CompareAndSwap(old, old|mask) {
+			ga.bitCnt.Add(1)
+			return true
+		}
+	}
+}
+
+// Get return true if given index is 1b, indexes never set are 0b
+func (ga *GrowableBitArray) Get(index int) bool {
+	checkIndex(index)
+	c := ga.chunkOf(index)
+	if c == nil {
+		return false
+	}
+	return c[(index>>uint64Bit)&(chunkWords-1)].Load()&(1<<(index%bitPerUnit)) != 0
+}
+
+// Unset given index to 0b
+func (ga *GrowableBitArray) Unset(index int) bool {
+	checkIndex(index)
+	c := ga.chunkOf(index)
+	if c == nil {
+		return false
+	}
+	w := &c[(index>>uint64Bit)&(chunkWords-1)]
+	mask := uint64(1) << (index % bitPerUnit)
+	for {
+		old := w.Load()
+		if old&mask == 0 {
+			return false
+		}
+		if w.CompareAndSwap(old, old&^mask) {
+			ga.bitCnt.Add(-1)
+			return true
+		}
+	}
+}
+
+// BitCnt bit 1 count
+func (ga *GrowableBitArray) BitCnt() int {
+	return int(ga.bitCnt.Sum())
+}
+
+// Clear all bits, allocated chunks are kept
+func (ga *GrowableBitArray) Clear() {
+	ga.eachChunk(func(_ int, c *chunk) bool {
+		for j := range c {
+			old := c[j].Swap(0)
+			ga.bitCnt.Add(-int64(bits.OnesCount64(old)))
+		}
+		return true
+	})
+}
+
+// Iter [index,set flag] up to Len
+func (ga *GrowableBitArray) Iter() iter.Seq2[int, bool] {
+	return func(yield func(int, bool) bool) {
+		for i := range ga.Len() {
+			if !yield(i, ga.Get(i)) {
+				break
+			}
+		}
+	}
+}
+
+// IterSet index of every 1b in ascending order, unallocated chunks and zero words are skipped
+func (ga *GrowableBitArray) IterSet() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		ga.eachChunk(func(ci int, c *chunk) bool {
+			for wi := range c {
+				word := c[wi].Load()
+				for word != 0 {
+					if !yield(ci<<chunkBit + wi<<uint64Bit + bits.TrailingZeros64(word)) {
+						return false
+					}
+					word &= word - 1
+				}
+			}
+			return true
+		})
+	}
+}
+
+// checkIndex reject negative indexes and math.MaxInt, whose Len would not fit in an int
+func checkIndex(index int) {
+	if index < 0 || index == math.MaxInt {
+		panic(fmt.Sprintf("index %d out of range", index))
+	}
+}
+
+func (ga *GrowableBitArray) chunkOf(index int) *chunk {
+	d := ga.dir.Load()
+	ci := index >> chunkBit
+	if ci>>(d.height*dirBits) != 0 {
+		return nil
+	}
+	n := d.root
+	for h := d.height - 1; h > 0; h-- {
+		n = n.kids[(ci>>(h*dirBits))&(dirFanout-1)].Load()
+		if n == nil {
+			return nil
+		}
+	}
+	return n.chunks[ci&(dirFanout-1)].Load()
+}
+
+// allocChunk slow path of Set, grow the directory and allocate the nodes and chunk holding index
+func (ga *GrowableBitArray) allocChunk(index int) *chunk {
+	ga.grow.Lock()
+	defer ga.grow.Unlock()
+	ci := index >> chunkBit
+	d := ga.dir.Load()
+	for ci>>(d.height*dirBits) != 0 {
+		root := newDirNode(false)
+		root.kids[0].Store(d.root)
+		d = &directory{height: d.height + 1, root: root}
+		ga.dir.Store(d)
+	}
+	n := d.root
+	for h := d.height - 1; h > 0; h-- {
+		slot := &n.kids[(ci>>(h*dirBits))&(dirFanout-1)]
+		if slot.Load() == nil {
+			slot.Store(newDirNode(h == 1))
+		}
+		n = slot.Load()
+	}
+	slot := &n.chunks[ci&(dirFanout-1)]
+	c := slot.Load()
+	if c == nil {
+		c = new(chunk)
+		slot.Store(c)
+	}
+	return c
+}
+
+// eachChunk call fn with every allocated chunk and its index in ascending order until fn returns false
+func (ga *GrowableBitArray) eachChunk(fn func(ci int, c *chunk) bool) {
+	d := ga.dir.Load()
+	var walk func(n *dirNode, h int, base int) bool
+	walk = func(n *dirNode, h int, base int) bool {
+		if h == 0 {
+			for i := range n.chunks {
+				if c := n.chunks[i].Load(); c != nil && !fn(base|i, c) {
+					return false
+				}
+			}
+			return true
+		}
+		for i := range n.kids {
+			if k := n.kids[i].Load(); k != nil && !walk(k, h-1, base|i<<(h*dirBits)) {
+				return false
+			}
+		}
+		return true
+	}
+	walk(d.root, d.height-1, 0)
+}
@@ -0,0 +1,82 @@
+package bitarray
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestGrowable(t *testing.T) {
+	ga := NewGrowable()
+	assert.False(t, ga.Get(1<<30))
+	assert.False(t, ga.Unset(5))
+	assert.True(t, ga.Set(5))
+	assert.False(t, ga.Set(5))
+	assert.True(t, ga.Set(1<<20))
+	assert.True(t, ga.Set(70000))
+	assert.Equal(t, 1<<20+1, ga.Len())
+	assert.True(t, ga.Get(70000))
+	assert.False(t, ga.Get(70001))
+	assert.Equal(t, []int{5, 70000, 1 << 20}, slices.Collect(ga.IterSet()))
+	assert.Equal(t, 3, ga.BitCnt())
+	assert.True(t, ga.Unset(70000))
+	assert.Equal(t, 2, ga.BitCnt())
+	ga.Clear()
+	assert.Equal(t, 0, ga.BitCnt())
+	assert.False(t, ga.Get(5))
+	assert.Panics(t, func() {
+		ga.Set(-1)
+	})
+}
+
+func TestGrowableConcurrent(t *testing.T) {
+	ga := NewGrowable()
+	var wg sync.WaitGroup
+	workers := 8
+	size := 1 << 18
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := w; i < size; i += workers {
+				ga.Set(i * 3)
+				assert.True(t, ga.Get(i*3))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, size, ga.BitCnt())
+	assert.Equal(t, (size-1)*3+1, ga.Len())
+	var cnt int
+	for i := range ga.IterSet() {
+		assert.Equal(t, 0, i%3)
+		cnt++
+	}
+	assert.Equal(t, size, cnt)
+}
+
+func TestGrowableSparse(t *testing.T) {
+	ga := NewGrowable()
+	huge := 1<<62 + 12345
+	assert.True(t, ga.Set(huge))
+	assert.True(t, ga.Set(3))
+	assert.True(t, ga.Set(1<<40))
+	assert.True(t, ga.Get(huge))
+	assert.False(t, ga.Get(huge-1))
+	assert.False(t, ga.Get(1<<50))
+	assert.Equal(t, huge+1, ga.Len())
+	assert.Equal(t, []int{3, 1 << 40, huge}, slices.Collect(ga.IterSet()))
+	assert.True(t, ga.Unset(huge))
+	assert.Equal(t, 2, ga.BitCnt())
+	assert.True(t, ga.Set(math.MaxInt-1))
+	assert.True(t, ga.Get(math.MaxInt-1))
+	assert.Equal(t, math.MaxInt, ga.Len())
+	assert.True(t, ga.Set(7))
+	assert.Equal(t, math.MaxInt, ga.Len())
+	assert.Panics(t, func() {
+		ga.Set(math.MaxInt)
+	})
+	assert.Equal(t, math.MaxInt, ga.Len())
+}
@@ -0,0 +1,233 @@
+package bitarray
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// BitMatrix dense rows x cols boolean matrix, each row is a run of stride words laid out like a BitArray
+// so row operations work a word at a time. Not safe for concurrent writes.
+type BitMatrix struct {
+	data   []uint64
+	rows   int
+	cols   int
+	stride int // words per row
+}
+
+func NewBitMatrix(rows, cols int) *BitMatrix {
+	if rows <= 0 || cols <= 0 {
+		panic("rows and cols must be greater than zero")
+	}
+	stride := (cols + bitPerUnit - 1) / bitPerUnit
+	return &BitMatrix{data: make([]uint64, rows*stride), rows: rows, cols: cols, stride: stride}
+}
+
+// Rows count of rows
+func (m *BitMatrix) Rows() int {
+	return m.rows
+}
+
+// Cols count of columns
+func (m *BitMatrix) Cols() int {
+	return m.cols
+}
+
+// Set cell (r, c) to 1b
+func (m *BitMatrix) Set(r, c int) bool {
+	w, mask := m.locate(r, c)
+	if *w&mask != 0 {
+		return false
+	}
+	*w |= mask
+	return true
+}
+
+// Get return true if cell (r, c) is 1b
+func (m *BitMatrix) Get(r, c int) bool {
+	w, mask := m.locate(r, c)
+	return *w&mask != 0
+}
+
+// Unset cell (r, c) to 0b
+func (m *BitMatrix) Unset(r, c int) bool {
+	w, mask := m.locate(r, c)
+	if *w&mask == 0 {
+		return false
+	}
+	*w &^= mask
+	return true
+}
+
+// BitCnt bit 1 count of the whole matrix
+func (m *BitMatrix) BitCnt() int {
+	var cnt int
+	for _, w := range m.data {
+		cnt += bits.OnesCount64(w)
+	}
+	return cnt
+}
+
+// Row copy of row r
+func (m *BitMatrix) Row(r int) *BitArray {
+	ba := NewBitArrayFrom(m.row(r))
+	ba.len = m.cols
+	return ba
+}
+
+// Col copy of column c
+func (m *BitMatrix) Col(c int) *BitArray {
+	ba := NewBitArray(m.rows)
+	for r := range m.rows {
+		if m.Get(r, c) {
+			ba.Set(r)
+		}
+	}
+	return ba
+}
+
+// RowCnt bit 1 count of row r
+func (m *BitMatrix) RowCnt(r int) int {
+	var cnt int
+	for _, w := range m.row(r) {
+		cnt += bits.OnesCount64(w)
+	}
+	return cnt
+}
+
+// ColCnt bit 1 count of column c
+func (m *BitMatrix) ColCnt(c int) int {
+	var cnt int
+	for r := range m.rows {
+		if m.Get(r, c) {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// OrRow row dst |= row src, return true if dst changed
+func (m *BitMatrix) OrRow(dst, src int) bool {
+	d, s := m.row(dst), m.row(src)
+	var changed bool
+	for i := range d {
+		if s[i]&^d[i] != 0 {
+			d[i] |= s[i]
+			changed = true
+		}
+	}
+	return changed
+}
+
+// AndRow row dst &= row src
+func (m *BitMatrix) AndRow(dst, src int) {
+	d, s := m.row(dst), m.row(src)
+	for i := range d {
+		d[i] &= s[i]
+	}
+}
+
+// ClearRow set every cell of row r to 0b
+func (m *BitMatrix) ClearRow(r int) {
+	clear(m.row(r))
+}
+
+// ClearCol set every cell of column c to 0b
+func (m *BitMatrix) ClearCol(c int) {
+	for r := range m.rows {
+		m.Unset(r, c)
+	}
+}
+
+// Transpose new cols x rows matrix with m[r][c] at [c][r]
+func (m *BitMatrix) Transpose() *BitMatrix {
+	t := NewBitMatrix(m.cols, m.rows)
+	for r := range m.rows {
+		for i, w := range m.row(r) {
+			for w != 0 {
+				c := i<<uint64Bit + bits.TrailingZeros64(w)
+				t.data[c*t.stride+r>>uint64Bit] |= 1 << (r % bitPerUnit)
+				w &= w - 1
+			}
+		}
+	}
+	return t
+}
+
+// Mul boolean product m x other, cell (i, j) is 1b if m[i][k] and other[k][j] for some k
+func (m *BitMatrix) Mul(other *BitMatrix) *BitMatrix {
+	if m.cols != other.rows {
+		panic(fmt.Sprintf("dimension mismatch %dx%d * %dx%d", m.rows, m.cols, other.rows, other.cols))
+	}
+	ret := NewBitMatrix(m.rows, other.cols)
+	for i := range m.rows {
+		dst := ret.row(i)
+		for wi, w := range m.row(i) {
+			for w != 0 {
+				k := wi<<uint64Bit + bits.TrailingZeros64(w)
+				for j, v := range other.row(k) {
+					dst[j] |= v
+				}
+				w &= w - 1
+			}
+		}
+	}
+	return ret
+}
+
+// Closure transitive closure in place by Warshall's algorithm over rows, afterwards m[i][j] is 1b
+// if j is reachable from i by a path of one or more edges. The matrix must be square.
+func (m *BitMatrix) Closure() {
+	if m.rows != m.cols {
+		panic(fmt.Sprintf("closure of non square matrix %dx%d", m.rows, m.cols))
+	}
+	for k := range m.rows {
+		for i := range m.rows {
+			if i != k && m.Get(i, k) {
+				m.OrRow(i, k)
+			}
+		}
+	}
+}
+
+// Equal return true if both matrices have the same shape and cells
+func (m *BitMatrix) Equal(other *BitMatrix) bool {
+	if m.rows != other.rows || m.cols != other.cols {
+		return false
+	}
+	for i := range m.data {
+		if m.data[i] != other.data[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *BitMatrix) String() string {
+	sb := strings.Builder{}
+	for r := range m.rows {
+		for c := range m.cols {
+			if m.Get(r, c) {
+				sb.WriteByte('1')
+			} else {
+				sb.WriteByte('0')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func (m *BitMatrix) row(r int) []uint64 {
+	if r < 0 || r >= m.rows {
+		panic(fmt.Sprintf("row %d out of range %d", r, m.rows))
+	}
+	return m.data[r*m.stride : (r+1)*m.stride]
+}
+
+func (m *BitMatrix) locate(r, c int) (*uint64, uint64) {
+	if c < 0 || c >= m.cols {
+		panic(fmt.Sprintf("col %d out of range %d", c, m.cols))
+	}
+	return &m.row(r)[c>>uint64Bit], 1 << (c % bitPerUnit)
+}
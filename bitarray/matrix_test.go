@@ -0,0 +1,99 @@
+package bitarray
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand/v2"
+	"testing"
+)
+
+func randomMatrix(rows, cols int, density float64) *BitMatrix {
+	m := NewBitMatrix(rows, cols)
+	for r := range rows {
+		for c := range cols {
+			if rand.Float64() < density {
+				m.Set(r, c)
+			}
+		}
+	}
+	return m
+}
+
+func TestMatrixRowCol(t *testing.T) {
+	m := NewBitMatrix(3, 70)
+	assert.True(t, m.Set(0, 1))
+	assert.False(t, m.Set(0, 1))
+	m.Set(0, 69)
+	m.Set(2, 69)
+	assert.Equal(t, 2, m.RowCnt(0))
+	assert.Equal(t, 2, m.ColCnt(69))
+	assert.Equal(t, 70, m.Row(0).Len())
+	assert.True(t, m.Row(0).Get(69))
+	assert.Equal(t, 3, m.Col(69).Len())
+	assert.Equal(t, 2, m.Col(69).BitCnt())
+	assert.True(t, m.OrRow(1, 0))
+	assert.False(t, m.OrRow(1, 0))
+	m.AndRow(1, 2)
+	assert.Equal(t, 1, m.RowCnt(1))
+	m.ClearCol(69)
+	assert.Equal(t, 1, m.BitCnt())
+	m.ClearRow(0)
+	assert.Equal(t, 0, m.BitCnt())
+	assert.Panics(t, func() {
+		m.Get(3, 0)
+	})
+	assert.Panics(t, func() {
+		m.Get(0, 70)
+	})
+}
+
+func TestMatrixTransposeMul(t *testing.T) {
+	a := randomMatrix(37, 130, 0.1)
+	b := randomMatrix(130, 65, 0.05)
+	at := a.Transpose()
+	assert.Equal(t, 130, at.Rows())
+	assert.True(t, at.Transpose().Equal(a))
+	for r := range a.Rows() {
+		for c := range a.Cols() {
+			assert.Equal(t, a.Get(r, c), at.Get(c, r))
+		}
+	}
+	p := a.Mul(b)
+	for i := range a.Rows() {
+		for j := range b.Cols() {
+			var want bool
+			for k := range a.Cols() {
+				want = want || a.Get(i, k) && b.Get(k, j)
+			}
+			assert.Equal(t, want, p.Get(i, j))
+		}
+	}
+	assert.Panics(t, func() {
+		a.Mul(a)
+	})
+}
+
+func TestMatrixClosure(t *testing.T) {
+	n := 90
+	m := randomMatrix(n, n, 0.02)
+	reach := make([][]bool, n)
+	for s := range n {
+		reach[s] = make([]bool, n)
+		stack := []int{s}
+		for len(stack) > 0 {
+			u := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for v := range n {
+				if m.Get(u, v) && !reach[s][v] {
+					reach[s][v] = true
+					stack = append(stack, v)
+				}
+			}
+		}
+	}
+	m.Closure()
+	for i := range n {
+		for j := range n {
+			assert.Equal(t, reach[i][j], m.Get(i, j))
+		}
+	}
+}
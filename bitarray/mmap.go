@@ -0,0 +1,173 @@
+//go:build unix
+
+package bitarray
+
+import (
+	"encoding/binary"
+	"fmt"
+	"golang.org/x/sys/unix"
+	"iter"
+	"math/bits"
+	"os"
+	"sync/atomic"
+	"unsafe"
+)
+
+const (
+	mmapMagic = 0x42414d31 // "BAM1"
+	// mmapHeader bytes before the words, keeps the words 8 byte aligned
+	mmapHeader = 64
+)
+
+var _ Bits = (*MmapBitArray)(nil)
+
+// MmapBitArray bit array whose words live in a shared memory mapped file, so the bits survive restarts
+// and the page cache holds them instead of the heap. Set and Unset are lock free CAS on the mapped words.
+type MmapBitArray struct {
+	file *os.File
+	mem  []byte
+	data []atomic.Uint64
+	len  int
+}
+
+// OpenMmap map the bit array stored at path, creating a zeroed one of size bits if the file does not exist.
+// An existing file must have been created with the same size.
+func OpenMmap(path string, size int) (*MmapBitArray, error) {
+	if size <= 0 {
+		panic("size must be greater than zero")
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	words := (size + bitPerUnit - 1) / bitPerUnit
+	total := int64(mmapHeader + 8*words)
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	fresh := fi.Size() == 0
+	if fresh {
+		if err = f.Truncate(total); err != nil {
+			f.Close()
+			return nil, err
+		}
+	} else if fi.Size() != total {
+		f.Close()
+		return nil, fmt.Errorf("%w: file size %d, want %d", ErrInvalidBitArray, fi.Size(), total)
+	}
+	mem, err := unix.Mmap(int(f.Fd()), 0, int(total), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if fresh {
+		binary.LittleEndian.PutUint32(mem, mmapMagic)
+		binary.LittleEndian.PutUint64(mem[8:], uint64(size))
+	} else if binary.LittleEndian.Uint32(mem) != mmapMagic || binary.LittleEndian.Uint64(mem[8:]) != uint64(size) {
+		unix.Munmap(mem)
+		f.Close()
+		return nil, fmt.Errorf("%w: header mismatch", ErrInvalidBitArray)
+	}
+	ma := &MmapBitArray{file: f, mem: mem, len: size}
+	ma.data = unsafe.Slice((*atomic.Uint64)(unsafe.Pointer(&mem[mmapHeader])), words)
+	return ma, nil
+}
+
+// Len array length
+func (ma *MmapBitArray) Len() int {
+	return ma.len
+}
+
+// Set given index to 1b
+func (ma *MmapBitArray) Set(index int) bool {
+	ma.check(index)
+	w := &ma.data[index>>uint64Bit]
+	mask := uint64(1) << (index % bitPerUnit)
+	for {
+		old := w.Load()
+		if old&mask != 0 {
+			return false
+		}
+		if w.CompareAndSwap(old, old|mask) {
+			return true
+		}
+	}
+}
+
+// Get return true if given index in bitarray is 1b
+func (ma *MmapBitArray) Get(index int) bool {
+	ma.check(index)
+	return ma.data[index>>uint64Bit].Load()&(1<<(index%bitPerUnit)) != 0
+}
+
+// Unset given index to 0b
+func (ma *MmapBitArray) Unset(index int) bool {
+	ma.check(index)
+	w := &ma.data[index>>uint64Bit]
+	mask := uint64(1) << (index % bitPerUnit)
+	for {
+		old := w.Load()
+		if old&mask == 0 {
+			return false
+		}
+		if w.CompareAndSwap(old, old&^mask) {
+			return true
+		}
+	}
+}
+
+// Clear all bits in the array
+func (ma *MmapBitArray) Clear() {
+	for i := range ma.data {
+		ma.data[i].Store(0)
+	}
+}
+
+// BitCnt bit 1 count, scans the whole mapping since no count is kept across restarts
+func (ma *MmapBitArray) BitCnt() int {
+	var cnt int
+	for i := range ma.data {
+		cnt += bits.OnesCount64(ma.data[i].Load())
+	}
+	return cnt
+}
+
+// Iter [index,set flag]
+func (ma *MmapBitArray) Iter() iter.Seq2[int, bool] {
+	return func(yield func(int, bool) bool) {
+		for i := range ma.len {
+			if !yield(i, ma.Get(i)) {
+				break
+			}
+		}
+	}
+}
+
+// Sync flush the dirty pages to the file and wait for the write to complete
+func (ma *MmapBitArray) Sync() error {
+	return unix.Msync(ma.mem, unix.MS_SYNC)
+}
+
+// Close sync and unmap the file, the array must not be used afterwards
+func (ma *MmapBitArray) Close() error {
+	if ma.mem == nil {
+		return nil
+	}
+	err := ma.Sync()
+	if e := unix.Munmap(ma.mem); err == nil {
+		err = e
+	}
+	if e := ma.file.Close(); err == nil {
+		err = e
+	}
+	ma.mem, ma.data = nil, nil
+	return err
+}
+
+func (ma *MmapBitArray) check(index int) {
+	if index < 0 || index >= ma.len {
+		panic(fmt.Sprintf("index %d out of range %d", index, ma.len))
+	}
+}
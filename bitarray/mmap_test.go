@@ -0,0 +1,59 @@
+//go:build unix
+
+package bitarray
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestMmapPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bits")
+	ma, err := OpenMmap(path, 1000)
+	assert.Nil(t, err)
+	assert.True(t, ma.Set(1))
+	assert.False(t, ma.Set(1))
+	assert.True(t, ma.Set(999))
+	assert.True(t, ma.Set(500))
+	assert.True(t, ma.Unset(500))
+	assert.Nil(t, ma.Sync())
+	assert.Nil(t, ma.Close())
+	assert.Nil(t, ma.Close())
+
+	ma, err = OpenMmap(path, 1000)
+	assert.Nil(t, err)
+	defer ma.Close()
+	assert.Equal(t, 1000, ma.Len())
+	assert.Equal(t, 2, ma.BitCnt())
+	assert.True(t, ma.Get(1))
+	assert.True(t, ma.Get(999))
+	assert.False(t, ma.Get(500))
+	assert.Panics(t, func() {
+		ma.Get(1000)
+	})
+
+	_, err = OpenMmap(path, 2000)
+	assert.ErrorIs(t, err, ErrInvalidBitArray)
+}
+
+func TestMmapConcurrentSet(t *testing.T) {
+	ma, err := OpenMmap(filepath.Join(t.TempDir(), "bits"), 1<<16)
+	assert.Nil(t, err)
+	defer ma.Close()
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := g; i < ma.Len(); i += 8 {
+				ma.Set(i)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1<<16, ma.BitCnt())
+	ma.Clear()
+	assert.Equal(t, 0, ma.BitCnt())
+}
@@ -0,0 +1,128 @@
+package bitarray
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// PackedArray fixed width unsigned integers of 1 to 32 bits packed into words. A value never straddles
+// two words, so every update is a single word CAS, widths not dividing 64 leave the top bits unused.
+type PackedArray struct {
+	data    []atomic.Uint64
+	len     int
+	width   int
+	perWord int
+	max     uint32
+}
+
+func NewPacked(size, width int) *PackedArray {
+	if size <= 0 {
+		panic("size must be greater than zero")
+	}
+	if width < 1 || width > 32 {
+		panic("width must be in [1, 32]")
+	}
+	perWord := bitPerUnit / width
+	return &PackedArray{data: make([]atomic.Uint64, (size+perWord-1)/perWord), len: size, width: width,
+		perWord: perWord, max: uint32(uint64(1)<<width - 1)}
+}
+
+// Len count of values
+func (pa *PackedArray) Len() int {
+	return pa.len
+}
+
+// Width bits per value
+func (pa *PackedArray) Width() int {
+	return pa.width
+}
+
+// Max largest storable value
+func (pa *PackedArray) Max() uint32 {
+	return pa.max
+}
+
+// Get value at index
+func (pa *PackedArray) Get(index int) uint32 {
+	w, shift := pa.locate(index)
+	return uint32(w.Load()>>shift) & pa.max
+}
+
+// Set store value at index and return the previous one
+func (pa *PackedArray) Set(index int, value uint32) uint32 {
+	pa.checkValue(value)
+	return pa.update(index, func(uint32) uint32 {
+		return value
+	})
+}
+
+// CompareAndSwap store neu at index if it holds old
+func (pa *PackedArray) CompareAndSwap(index int, old, neu uint32) bool {
+	pa.checkValue(neu)
+	w, shift := pa.locate(index)
+	mask := uint64(pa.max) << shift
+	for {
+		cur := w.Load()
+		if uint32(cur>>shift)&pa.max != old {
+			return false
+		}
+		if w.CompareAndSwap(cur, cur&^mask|uint64(neu)<<shift) {
+			return true
+		}
+	}
+}
+
+// Increment add one to the value at index unless it is already Max, return the new value
+func (pa *PackedArray) Increment(index int) uint32 {
+	return pa.saturate(pa.update(index, pa.saturate))
+}
+
+// saturate v+1 capped at Max
+func (pa *PackedArray) saturate(v uint32) uint32 {
+	if v == pa.max {
+		return v
+	}
+	return v + 1
+}
+
+// Decrement subtract one from the value at index unless it is already zero, return the new value
+func (pa *PackedArray) Decrement(index int) uint32 {
+	old := pa.update(index, func(v uint32) uint32 {
+		return max(v, 1) - 1
+	})
+	return max(old, 1) - 1
+}
+
+// Clear set every value to zero
+func (pa *PackedArray) Clear() {
+	for i := range pa.data {
+		pa.data[i].Store(0)
+	}
+}
+
+// update CAS the value at index to fn(old), return the old value
+func (pa *PackedArray) update(index int, fn func(old uint32) uint32) uint32 {
+	w, shift := pa.locate(index)
+	mask := uint64(pa.max) << shift
+	for {
+		cur := w.Load()
+		old := uint32(cur>>shift) & pa.max
+		neu := fn(old)
+		if old == neu || w.CompareAndSwap(cur, cur&^mask|uint64(neu)<<shift) {
+			return old
+		}
+	}
+}
+
+func (pa *PackedArray) locate(index int) (*atomic.Uint64, int) {
+	if index < 0 || index >= pa.len {
+		panic(fmt.Sprintf("index %d out of range %d", index, pa.len))
+	}
+	return &pa.data[index/pa.perWord], index % pa.perWord * pa.width
+}
+
+func (pa *PackedArray) checkValue(v uint32) {
+	if v > pa.max {
+		panic(fmt.Sprintf("value %d exceeds width %d", v, pa.width))
+	}
+}
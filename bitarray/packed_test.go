@@ -0,0 +1,69 @@
+package bitarray
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestPackedGetSet(t *testing.T) {
+	for _, width := range []int{1, 3, 4, 7, 32} {
+		pa := NewPacked(200, width)
+		for i := range pa.Len() {
+			pa.Set(i, uint32(i)&pa.Max())
+		}
+		for i := range pa.Len() {
+			assert.Equal(t, uint32(i)&pa.Max(), pa.Get(i))
+		}
+		assert.Equal(t, uint32(5)&pa.Max(), pa.Set(5, 0))
+		assert.Equal(t, uint32(0), pa.Get(5))
+		assert.Equal(t, uint32(6)&pa.Max(), pa.Get(6))
+	}
+	assert.Equal(t, uint32(math.MaxUint32), NewPacked(1, 32).Max())
+	assert.Panics(t, func() {
+		NewPacked(10, 4).Set(0, 16)
+	})
+	assert.Panics(t, func() {
+		NewPacked(10, 33)
+	})
+}
+
+func TestPackedCASAndSaturate(t *testing.T) {
+	pa := NewPacked(10, 4)
+	assert.True(t, pa.CompareAndSwap(3, 0, 9))
+	assert.False(t, pa.CompareAndSwap(3, 0, 1))
+	assert.Equal(t, uint32(9), pa.Get(3))
+	for range 20 {
+		pa.Increment(4)
+	}
+	assert.Equal(t, uint32(15), pa.Get(4))
+	assert.Equal(t, uint32(15), pa.Increment(4))
+	assert.Equal(t, uint32(14), pa.Decrement(4))
+	assert.Equal(t, uint32(0), pa.Decrement(5))
+	assert.Equal(t, uint32(9), pa.Get(3))
+
+	wide := NewPacked(1, 32)
+	wide.Set(0, math.MaxUint32)
+	assert.Equal(t, uint32(math.MaxUint32), wide.Increment(0))
+	pa.Clear()
+	assert.Equal(t, uint32(0), pa.Get(3))
+}
+
+func TestPackedConcurrentIncrement(t *testing.T) {
+	pa := NewPacked(16, 8)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 16 * 30 {
+				pa.Increment(i % 16)
+			}
+		}()
+	}
+	wg.Wait()
+	for i := range 16 {
+		assert.Equal(t, uint32(240), pa.Get(i))
+	}
+}
@@ -0,0 +1,69 @@
+package bitarray
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// SetRange set bits in [lo,hi) to 1b
+func (ab *SyncBitArray) SetRange(lo, hi int) {
+	ab.gate.enter()
+	defer ab.gate.exit()
+	ab.eachRangeWord(lo, hi, func(i int, mask uint64) {
+		ab.updateWord(i, func(old uint64) uint64 {
+			return old | mask
+		})
+	})
+}
+
+// ClearRange set bits in [lo,hi) to 0b
+func (ab *SyncBitArray) ClearRange(lo, hi int) {
+	ab.gate.enter()
+	defer ab.gate.exit()
+	ab.eachRangeWord(lo, hi, func(i int, mask uint64) {
+		ab.updateWord(i, func(old uint64) uint64 {
+			return old &^ mask
+		})
+	})
+}
+
+// FlipRange flip bits in [lo,hi)
+func (ab *SyncBitArray) FlipRange(lo, hi int) {
+	ab.gate.enter()
+	defer ab.gate.exit()
+	ab.eachRangeWord(lo, hi, func(i int, mask uint64) {
+		ab.updateWord(i, func(old uint64) uint64 {
+			return old ^ mask
+		})
+	})
+}
+
+// CountRange count of 1b in [lo,hi)
+func (ab *SyncBitArray) CountRange(lo, hi int) int {
+	var cnt int
+	ab.eachRangeWord(lo, hi, func(i int, mask uint64) {
+		cnt += bits.OnesCount64(ab.data[i].Load() & mask)
+	})
+	return cnt
+}
+
+// eachRangeWord call fn with every word overlapping [lo,hi) and the mask of the overlapped bits
+func (ab *SyncBitArray) eachRangeWord(lo, hi int, fn func(i int, mask uint64)) {
+	if lo < 0 || hi > ab.len || lo > hi {
+		panic(fmt.Sprintf("range [%d,%d) out of range %d", lo, hi, ab.len))
+	}
+	if lo == hi {
+		return
+	}
+	first, last := lo>>uint64Bit, (hi-1)>>uint64Bit
+	for i := first; i <= last; i++ {
+		mask := ^uint64(0)
+		if i == first {
+			mask &= ^uint64(0) << (lo % bitPerUnit)
+		}
+		if i == last {
+			mask &= ^uint64(0) >> (bitPerUnit - 1 - (hi-1)%bitPerUnit)
+		}
+		fn(i, mask)
+	}
+}
@@ -0,0 +1,75 @@
+package bitarray
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestRangeOp(t *testing.T) {
+	ba := New(200)
+	ba.SetRange(3, 130)
+	assert.Equal(t, 127, ba.BitCnt())
+	assert.Equal(t, 127, ba.CountRange(0, 200))
+	assert.Equal(t, 3, ba.NextSet(0))
+	assert.Equal(t, 129, ba.PrevSet(199))
+	ba.ClearRange(64, 128)
+	assert.Equal(t, 63, ba.BitCnt())
+	assert.Equal(t, 61, ba.CountRange(3, 64))
+	ba.FlipRange(60, 70)
+	assert.Equal(t, 63-4+6, ba.BitCnt())
+	assert.Equal(t, 0, ba.CountRange(60, 64))
+	assert.Equal(t, 6, ba.CountRange(64, 70))
+	ba.SetRange(5, 5)
+	assert.Equal(t, 0, ba.CountRange(5, 5))
+	ba.SetRange(0, 200)
+	assert.Equal(t, 200, ba.BitCnt())
+	assert.Panics(t, func() {
+		ba.SetRange(10, 201)
+	})
+	assert.Panics(t, func() {
+		ba.CountRange(10, 9)
+	})
+}
+
+func TestRangeOpRandom(t *testing.T) {
+	length := 1000
+	ba := New(length)
+	want := make([]bool, length)
+	for range 200 {
+		lo := rand.IntN(length)
+		hi := lo + rand.IntN(length-lo+1)
+		switch rand.IntN(3) {
+		case 0:
+			ba.SetRange(lo, hi)
+			for i := lo; i < hi; i++ {
+				want[i] = true
+			}
+		case 1:
+			ba.ClearRange(lo, hi)
+			for i := lo; i < hi; i++ {
+				want[i] = false
+			}
+		case 2:
+			ba.FlipRange(lo, hi)
+			for i := lo; i < hi; i++ {
+				want[i] = !want[i]
+			}
+		}
+		var cnt int
+		for i := lo; i < hi; i++ {
+			if want[i] {
+				cnt++
+			}
+		}
+		assert.Equal(t, cnt, ba.CountRange(lo, hi))
+	}
+	var cnt int
+	for i, b := range ba.Iter() {
+		assert.Equal(t, want[i], b)
+		if b {
+			cnt++
+		}
+	}
+	assert.Equal(t, cnt, ba.BitCnt())
+}
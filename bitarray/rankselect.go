@@ -0,0 +1,122 @@
+package bitarray
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+// blockWords words per rank block, ranks are stored once per 512 bits
+const blockWords = 8
+
+// selectSample one select hint is kept every selectSample 1b
+const selectSample = 512
+
+// FrozenBitArray read only copy of a bit array with rank and select indexes
+type FrozenBitArray struct {
+	data    []uint64
+	len     int
+	ones    int
+	ranks   []int // count of 1b before each block
+	samples []int // block holding the (j*selectSample)-th 1b
+}
+
+// Freeze copy the current bits into a read only array and build its rank/select tables,
+// the copy is a consistent Snapshot and later writes to ab are not seen by it
+func (ab *SyncBitArray) Freeze() *FrozenBitArray {
+	data := ab.Snapshot().data
+	fa := &FrozenBitArray{data: data, len: ab.len}
+	blocks := (len(data) + blockWords - 1) / blockWords
+	fa.ranks = make([]int, blocks+1)
+	var ones int
+	for b := range blocks {
+		fa.ranks[b] = ones
+		for _, w := range data[b*blockWords : min((b+1)*blockWords, len(data))] {
+			cnt := bits.OnesCount64(w)
+			// the block reaching the next multiple of selectSample is the hint of that 1b
+			for len(fa.samples)*selectSample < ones+cnt {
+				fa.samples = append(fa.samples, b)
+			}
+			ones += cnt
+		}
+	}
+	fa.ranks[blocks] = ones
+	fa.ones = ones
+	return fa
+}
+
+// Len array length
+func (fa *FrozenBitArray) Len() int {
+	return fa.len
+}
+
+// BitCnt bit 1 count
+func (fa *FrozenBitArray) BitCnt() int {
+	return fa.ones
+}
+
+// Get return true if given index is 1b
+func (fa *FrozenBitArray) Get(index int) bool {
+	if index < 0 || index >= fa.len {
+		panic(fmt.Sprintf("index %d out of range %d", index, fa.len))
+	}
+	return fa.data[index>>uint64Bit]&(1<<(index%bitPerUnit)) != 0
+}
+
+// Rank1 count of 1b in [0,i), i in [0,Len]
+func (fa *FrozenBitArray) Rank1(i int) int {
+	if i < 0 || i > fa.len {
+		panic(fmt.Sprintf("index %d out of range %d", i, fa.len))
+	}
+	wIdx := i >> uint64Bit
+	b := wIdx / blockWords
+	r := fa.ranks[b]
+	for w := b * blockWords; w < wIdx; w++ {
+		r += bits.OnesCount64(fa.data[w])
+	}
+	if off := i % bitPerUnit; off != 0 {
+		r += bits.OnesCount64(fa.data[wIdx] & (1<<off - 1))
+	}
+	return r
+}
+
+// Rank0 count of 0b in [0,i)
+func (fa *FrozenBitArray) Rank0(i int) int {
+	return i - fa.Rank1(i)
+}
+
+// Select1 index of the k-th 1b counting from 0, so Rank1(Select1(k)) == k, -1 if k >= BitCnt
+func (fa *FrozenBitArray) Select1(k int) int {
+	if k < 0 {
+		panic("k must not be negative")
+	}
+	if k >= fa.ones {
+		return -1
+	}
+	lo := fa.samples[k/selectSample]
+	hi := len(fa.ranks) - 1
+	if s := k/selectSample + 1; s < len(fa.samples) {
+		hi = fa.samples[s] + 1
+	}
+	// last block in [lo,hi) whose rank is not above k
+	b := lo + sort.Search(hi-lo, func(j int) bool {
+		return fa.ranks[lo+j] > k
+	}) - 1
+	k -= fa.ranks[b]
+	for w := b * blockWords; ; w++ {
+		word := fa.data[w]
+		cnt := bits.OnesCount64(word)
+		if k < cnt {
+			return w<<uint64Bit + selectInWord(word, k)
+		}
+		k -= cnt
+	}
+}
+
+// selectInWord position of the k-th 1b of word, k must be below its popcount
+func selectInWord(word uint64, k int) int {
+	for range k {
+		word &= word - 1
+	}
+	return bits.TrailingZeros64(word)
+}
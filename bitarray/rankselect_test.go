@@ -0,0 +1,47 @@
+package bitarray
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestRankSelect(t *testing.T) {
+	ba := newWith(200, 0, 5, 64, 65, 130, 199)
+	fa := ba.Freeze()
+	ba.Set(1)
+	assert.False(t, fa.Get(1))
+	assert.Equal(t, 6, fa.BitCnt())
+	assert.Equal(t, 0, fa.Rank1(0))
+	assert.Equal(t, 1, fa.Rank1(1))
+	assert.Equal(t, 2, fa.Rank1(64))
+	assert.Equal(t, 4, fa.Rank1(66))
+	assert.Equal(t, 6, fa.Rank1(200))
+	assert.Equal(t, 194, fa.Rank0(200))
+	for k, want := range []int{0, 5, 64, 65, 130, 199} {
+		assert.Equal(t, want, fa.Select1(k))
+	}
+	assert.Equal(t, -1, fa.Select1(6))
+	assert.Equal(t, -1, New(10).Freeze().Select1(0))
+}
+
+func TestRankSelectRandom(t *testing.T) {
+	for _, density := range []int{1, 3, 50, 1000} {
+		length := 100000 + rand.IntN(1000)
+		ba := New(length)
+		positions := make([]int, 0)
+		for i := range length {
+			if rand.IntN(density) == 0 {
+				ba.Set(i)
+				positions = append(positions, i)
+			}
+		}
+		fa := ba.Freeze()
+		assert.Equal(t, len(positions), fa.BitCnt())
+		for k, p := range positions {
+			assert.Equal(t, p, fa.Select1(k))
+			assert.Equal(t, k, fa.Rank1(p))
+		}
+		assert.Equal(t, len(positions), fa.Rank1(length))
+	}
+}
@@ -0,0 +1,652 @@
+package bitarray
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+const (
+	// arrayMaxSize containers with more values than this are stored as bitmaps
+	arrayMaxSize      = 4096
+	bitmapWords       = 1 << 16 / bitPerUnit
+	serialCookieNoRun = 12346
+	serialCookie      = 12347
+	noOffsetThreshold = 4
+)
+
+var ErrInvalidRoaring = errors.New("invalid roaring bitmap")
+
+type containerKind uint8
+
+const (
+	arrayKind containerKind = iota
+	bitmapKind
+	runKind
+)
+
+// interval closed range [start,last] of a run container
+type interval struct {
+	start uint16
+	last  uint16
+}
+
+// container the values of one 64K chunk sharing the same high 16 bits
+type container struct {
+	kind   containerKind
+	card   int
+	array  []uint16
+	bitmap []uint64
+	runs   []interval
+}
+
+func (c *container) contains(x uint16) bool {
+	switch c.kind {
+	case arrayKind:
+		_, ok := slices.BinarySearch(c.array, x)
+		return ok
+	case bitmapKind:
+		return c.bitmap[x>>uint64Bit]&(1<<(x%bitPerUnit)) != 0
+	default:
+		i, _ := slices.BinarySearchFunc(c.runs, x, func(r interval, x uint16) int {
+			if r.last < x {
+				return -1
+			}
+			if r.start > x {
+				return 1
+			}
+			return 0
+		})
+		return i < len(c.runs) && c.runs[i].start <= x && x <= c.runs[i].last
+	}
+}
+
+func (c *container) add(x uint16) bool {
+	if c.kind == runKind {
+		if c.contains(x) {
+			return false
+		}
+		c.unrun()
+	}
+	if c.kind == arrayKind {
+		i, ok := slices.BinarySearch(c.array, x)
+		if ok {
+			return false
+		}
+		if c.card < arrayMaxSize {
+			c.array = slices.Insert(c.array, i, x)
+			c.card++
+			return true
+		}
+		c.toBitmap()
+	}
+	w := &c.bitmap[x>>uint64Bit]
+	mask := uint64(1) << (x % bitPerUnit)
+	if *w&mask != 0 {
+		return false
+	}
+	*w |= mask
+	c.card++
+	return true
+}
+
+func (c *container) remove(x uint16) bool {
+	if !c.contains(x) {
+		return false
+	}
+	if c.kind == runKind {
+		c.unrun()
+	}
+	if c.kind == arrayKind {
+		i, _ := slices.BinarySearch(c.array, x)
+		c.array = slices.Delete(c.array, i, i+1)
+		c.card--
+		return true
+	}
+	c.bitmap[x>>uint64Bit] &^= 1 << (x % bitPerUnit)
+	c.card--
+	if c.card <= arrayMaxSize {
+		c.toArray()
+	}
+	return true
+}
+
+// words bits of the container as a bitmap, shared with c for bitmap containers
+func (c *container) words() []uint64 {
+	if c.kind == bitmapKind {
+		return c.bitmap
+	}
+	ws := make([]uint64, bitmapWords)
+	switch c.kind {
+	case arrayKind:
+		for _, x := range c.array {
+			ws[x>>uint64Bit] |= 1 << (x % bitPerUnit)
+		}
+	case runKind:
+		for _, r := range c.runs {
+			for x := int(r.start); x <= int(r.last); x++ {
+				ws[x>>uint64Bit] |= 1 << (x % bitPerUnit)
+			}
+		}
+	}
+	return ws
+}
+
+func (c *container) values() []uint16 {
+	switch c.kind {
+	case arrayKind:
+		return c.array
+	case bitmapKind:
+		vs := make([]uint16, 0, c.card)
+		for i, w := range c.bitmap {
+			for w != 0 {
+				vs = append(vs, uint16(i<<uint64Bit+bits.TrailingZeros64(w)))
+				w &= w - 1
+			}
+		}
+		return vs
+	default:
+		vs := make([]uint16, 0, c.card)
+		for _, r := range c.runs {
+			for x := int(r.start); x <= int(r.last); x++ {
+				vs = append(vs, uint16(x))
+			}
+		}
+		return vs
+	}
+}
+
+func (c *container) toBitmap() {
+	c.bitmap = c.words()
+	c.array, c.runs = nil, nil
+	c.kind = bitmapKind
+}
+
+func (c *container) toArray() {
+	c.array = c.values()
+	c.bitmap, c.runs = nil, nil
+	c.kind = arrayKind
+}
+
+// unrun convert a run container to the array or bitmap form fitting its cardinality
+func (c *container) unrun() {
+	if c.card <= arrayMaxSize {
+		c.toArray()
+	} else {
+		c.toBitmap()
+	}
+}
+
+func (c *container) clone() *container {
+	return &container{kind: c.kind, card: c.card,
+		array: slices.Clone(c.array), bitmap: slices.Clone(c.bitmap), runs: slices.Clone(c.runs)}
+}
+
+// runCount count of runs of consecutive values
+func (c *container) runCount() int {
+	switch c.kind {
+	case runKind:
+		return len(c.runs)
+	case arrayKind:
+		n := 0
+		for i, x := range c.array {
+			if i == 0 || c.array[i-1]+1 != x {
+				n++
+			}
+		}
+		return n
+	default:
+		n := 0
+		for i, w := range c.bitmap {
+			// a run starts at every 1b whose lower neighbour is 0b
+			prev := w << 1
+			if i > 0 {
+				prev |= c.bitmap[i-1] >> (bitPerUnit - 1)
+			}
+			n += bits.OnesCount64(w &^ prev)
+		}
+		return n
+	}
+}
+
+// optimize pick the smallest serialized form among array, bitmap and runs
+func (c *container) optimize() {
+	runs := c.runCount()
+	runSize := 2 + 4*runs
+	otherSize := bitmapWords * 8
+	if c.card <= arrayMaxSize {
+		otherSize = 2 * c.card
+	}
+	if runSize >= otherSize {
+		if c.kind == runKind {
+			c.unrun()
+		}
+		return
+	}
+	if c.kind == runKind {
+		return
+	}
+	vs := c.values()
+	rs := make([]interval, 0, runs)
+	for i, x := range vs {
+		if i > 0 && vs[i-1]+1 == x {
+			rs[len(rs)-1].last = x
+		} else {
+			rs = append(rs, interval{start: x, last: x})
+		}
+	}
+	c.runs = rs
+	c.array, c.bitmap = nil, nil
+	c.kind = runKind
+}
+
+func (c *container) serializedSize() int {
+	switch c.kind {
+	case arrayKind:
+		return 2 * c.card
+	case bitmapKind:
+		return bitmapWords * 8
+	default:
+		return 2 + 4*len(c.runs)
+	}
+}
+
+func newContainerOfWords(ws []uint64) *container {
+	var card int
+	for _, w := range ws {
+		card += bits.OnesCount64(w)
+	}
+	c := &container{kind: bitmapKind, card: card, bitmap: ws}
+	if card <= arrayMaxSize {
+		c.toArray()
+	}
+	return c
+}
+
+// combineContainers apply op word by word, arrays are merged directly
+func combineContainers(a, b *container, op func(a, b uint64) uint64) *container {
+	if a.kind == arrayKind && b.kind == arrayKind {
+		return combineArrays(a.array, b.array, op)
+	}
+	wa, wb := a.words(), b.words()
+	ws := make([]uint64, bitmapWords)
+	for i := range ws {
+		ws[i] = op(wa[i], wb[i])
+	}
+	return newContainerOfWords(ws)
+}
+
+func combineArrays(a, b []uint16, op func(a, b uint64) uint64) *container {
+	// op on single bits tells which side of the merge is kept
+	keepA, keepB, keepBoth := op(1, 0) == 1, op(0, 1) == 1, op(1, 1) == 1
+	vs := make([]uint16, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i] < b[j]):
+			if keepA {
+				vs = append(vs, a[i])
+			}
+			i++
+		case i == len(a) || b[j] < a[i]:
+			if keepB {
+				vs = append(vs, b[j])
+			}
+			j++
+		default:
+			if keepBoth {
+				vs = append(vs, a[i])
+			}
+			i++
+			j++
+		}
+	}
+	c := &container{kind: arrayKind, card: len(vs), array: vs}
+	if c.card > arrayMaxSize {
+		c.toBitmap()
+	}
+	return c
+}
+
+// RoaringBitmap compressed set of uint32, values are split by their high 16 bits into containers stored as
+// a sorted array, a 64K bitmap or a list of runs, whichever is smaller. Not safe for concurrent writes.
+type RoaringBitmap struct {
+	keys       []uint16
+	containers []*container
+}
+
+func NewRoaring() *RoaringBitmap {
+	return &RoaringBitmap{}
+}
+
+// Add x, return false if already present
+func (rb *RoaringBitmap) Add(x uint32) bool {
+	hi, lo := uint16(x>>16), uint16(x)
+	i, ok := slices.BinarySearch(rb.keys, hi)
+	if !ok {
+		rb.keys = slices.Insert(rb.keys, i, hi)
+		rb.containers = slices.Insert(rb.containers, i, &container{kind: arrayKind})
+	}
+	return rb.containers[i].add(lo)
+}
+
+// Remove x, return false if absent
+func (rb *RoaringBitmap) Remove(x uint32) bool {
+	hi, lo := uint16(x>>16), uint16(x)
+	i, ok := slices.BinarySearch(rb.keys, hi)
+	if !ok || !rb.containers[i].remove(lo) {
+		return false
+	}
+	if rb.containers[i].card == 0 {
+		rb.keys = slices.Delete(rb.keys, i, i+1)
+		rb.containers = slices.Delete(rb.containers, i, i+1)
+	}
+	return true
+}
+
+// Contains return true if x is present
+func (rb *RoaringBitmap) Contains(x uint32) bool {
+	i, ok := slices.BinarySearch(rb.keys, uint16(x>>16))
+	return ok && rb.containers[i].contains(uint16(x))
+}
+
+// Cardinality count of values
+func (rb *RoaringBitmap) Cardinality() int {
+	var n int
+	for _, c := range rb.containers {
+		n += c.card
+	}
+	return n
+}
+
+// Iter values in ascending order
+func (rb *RoaringBitmap) Iter() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for i, c := range rb.containers {
+			hi := uint32(rb.keys[i]) << 16
+			for _, v := range c.values() {
+				if !yield(hi | uint32(v)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Clone deep copy
+func (rb *RoaringBitmap) Clone() *RoaringBitmap {
+	ret := &RoaringBitmap{keys: slices.Clone(rb.keys), containers: make([]*container, len(rb.containers))}
+	for i, c := range rb.containers {
+		ret.containers[i] = c.clone()
+	}
+	return ret
+}
+
+// RunOptimize convert the containers to runs where it is smaller, and back where it is not
+func (rb *RoaringBitmap) RunOptimize() {
+	for _, c := range rb.containers {
+		c.optimize()
+	}
+}
+
+// And keep only the values also in other
+func (rb *RoaringBitmap) And(other *RoaringBitmap) {
+	rb.combine(other, and, false, false)
+}
+
+// Or add the values of other
+func (rb *RoaringBitmap) Or(other *RoaringBitmap) {
+	rb.combine(other, or, true, true)
+}
+
+// Xor keep the values in exactly one of rb and other
+func (rb *RoaringBitmap) Xor(other *RoaringBitmap) {
+	rb.combine(other, xor, true, true)
+}
+
+// AndNot remove the values of other
+func (rb *RoaringBitmap) AndNot(other *RoaringBitmap) {
+	rb.combine(other, andNot, true, false)
+}
+
+// AndCardinality count of values in both bitmaps
+func (rb *RoaringBitmap) AndCardinality(other *RoaringBitmap) int {
+	var n int
+	i, j := 0, 0
+	for i < len(rb.keys) && j < len(other.keys) {
+		switch {
+		case rb.keys[i] < other.keys[j]:
+			i++
+		case rb.keys[i] > other.keys[j]:
+			j++
+		default:
+			n += combineContainers(rb.containers[i], other.containers[j], and).card
+			i++
+			j++
+		}
+	}
+	return n
+}
+
+// combine merge the containers by key, keepA/keepB tell whether containers present on one side only are kept
+func (rb *RoaringBitmap) combine(other *RoaringBitmap, op func(a, b uint64) uint64, keepA, keepB bool) {
+	keys := make([]uint16, 0, len(rb.keys)+len(other.keys))
+	cs := make([]*container, 0, len(rb.keys)+len(other.keys))
+	i, j := 0, 0
+	for i < len(rb.keys) || j < len(other.keys) {
+		switch {
+		case j == len(other.keys) || (i < len(rb.keys) && rb.keys[i] < other.keys[j]):
+			if keepA {
+				keys, cs = append(keys, rb.keys[i]), append(cs, rb.containers[i])
+			}
+			i++
+		case i == len(rb.keys) || other.keys[j] < rb.keys[i]:
+			if keepB {
+				keys, cs = append(keys, other.keys[j]), append(cs, other.containers[j].clone())
+			}
+			j++
+		default:
+			c := combineContainers(rb.containers[i], other.containers[j], op)
+			if c.card > 0 {
+				keys, cs = append(keys, rb.keys[i]), append(cs, c)
+			}
+			i++
+			j++
+		}
+	}
+	rb.keys, rb.containers = keys, cs
+}
+
+// MarshalBinary encode in the portable Roaring serialization format
+func (rb *RoaringBitmap) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if _, err := rb.WriteTo(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decode data in the portable Roaring serialization format
+func (rb *RoaringBitmap) UnmarshalBinary(data []byte) error {
+	_, err := rb.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// WriteTo write the portable Roaring serialization format to w
+func (rb *RoaringBitmap) WriteTo(w io.Writer) (int64, error) {
+	n := len(rb.containers)
+	hasRun := slices.ContainsFunc(rb.containers, func(c *container) bool {
+		return c.kind == runKind
+	})
+	header := make([]byte, 0, 8+8*n)
+	var headerSize int
+	if hasRun {
+		header = binary.LittleEndian.AppendUint32(header, serialCookie|uint32(n-1)<<16)
+		flags := make([]byte, (n+7)/8)
+		for i, c := range rb.containers {
+			if c.kind == runKind {
+				flags[i/8] |= 1 << (i % 8)
+			}
+		}
+		header = append(header, flags...)
+		headerSize = 4 + len(flags) + 4*n
+	} else {
+		header = binary.LittleEndian.AppendUint32(header, serialCookieNoRun)
+		header = binary.LittleEndian.AppendUint32(header, uint32(n))
+		headerSize = 8 + 4*n
+	}
+	for i, c := range rb.containers {
+		header = binary.LittleEndian.AppendUint16(header, rb.keys[i])
+		header = binary.LittleEndian.AppendUint16(header, uint16(c.card-1))
+	}
+	if !hasRun || n >= noOffsetThreshold {
+		offset := headerSize + 4*n
+		for _, c := range rb.containers {
+			header = binary.LittleEndian.AppendUint32(header, uint32(offset))
+			offset += c.serializedSize()
+		}
+	}
+	written, err := w.Write(header)
+	total := int64(written)
+	if err != nil {
+		return total, err
+	}
+	for _, c := range rb.containers {
+		body := make([]byte, 0, c.serializedSize())
+		switch c.kind {
+		case arrayKind:
+			for _, v := range c.array {
+				body = binary.LittleEndian.AppendUint16(body, v)
+			}
+		case bitmapKind:
+			for _, v := range c.bitmap {
+				body = binary.LittleEndian.AppendUint64(body, v)
+			}
+		case runKind:
+			body = binary.LittleEndian.AppendUint16(body, uint16(len(c.runs)))
+			for _, r := range c.runs {
+				body = binary.LittleEndian.AppendUint16(body, r.start)
+				body = binary.LittleEndian.AppendUint16(body, r.last-r.start)
+			}
+		}
+		written, err = w.Write(body)
+		total += int64(written)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// ReadFrom replace the content by the portable Roaring serialization read from r
+func (rb *RoaringBitmap) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+	var cookie uint32
+	if err := binary.Read(cr, binary.LittleEndian, &cookie); err != nil {
+		return cr.n, err
+	}
+	var n int
+	var runFlags []byte
+	switch {
+	case cookie&0xFFFF == serialCookie:
+		n = int(cookie>>16) + 1
+		runFlags = make([]byte, (n+7)/8)
+		if _, err := io.ReadFull(cr, runFlags); err != nil {
+			return cr.n, err
+		}
+	case cookie == serialCookieNoRun:
+		var size uint32
+		if err := binary.Read(cr, binary.LittleEndian, &size); err != nil {
+			return cr.n, err
+		}
+		n = int(size)
+		if n > 1<<16 {
+			return cr.n, ErrInvalidRoaring
+		}
+	default:
+		return cr.n, ErrInvalidRoaring
+	}
+	desc := make([]uint16, 2*n)
+	if err := binary.Read(cr, binary.LittleEndian, desc); err != nil {
+		return cr.n, err
+	}
+	if runFlags == nil || n >= noOffsetThreshold {
+		if _, err := io.CopyN(io.Discard, cr, int64(4*n)); err != nil {
+			return cr.n, err
+		}
+	}
+	keys := make([]uint16, n)
+	cs := make([]*container, n)
+	for i := range n {
+		keys[i] = desc[2*i]
+		if i > 0 && keys[i] <= keys[i-1] {
+			return cr.n, ErrInvalidRoaring
+		}
+		card := int(desc[2*i+1]) + 1
+		c := &container{card: card}
+		switch {
+		case runFlags != nil && runFlags[i/8]&(1<<(i%8)) != 0:
+			var nRuns uint16
+			if err := binary.Read(cr, binary.LittleEndian, &nRuns); err != nil {
+				return cr.n, err
+			}
+			raw := make([]uint16, 2*int(nRuns))
+			if err := binary.Read(cr, binary.LittleEndian, raw); err != nil {
+				return cr.n, err
+			}
+			c.kind = runKind
+			c.runs = make([]interval, nRuns)
+			sum := 0
+			for j := range c.runs {
+				if int(raw[2*j])+int(raw[2*j+1]) > 0xFFFF {
+					return cr.n, ErrInvalidRoaring
+				}
+				c.runs[j] = interval{start: raw[2*j], last: raw[2*j] + raw[2*j+1]}
+				// runs must be sorted and neither overlap nor touch, else they were never merged
+				if j > 0 && int(c.runs[j].start) <= int(c.runs[j-1].last)+1 {
+					return cr.n, ErrInvalidRoaring
+				}
+				sum += int(raw[2*j+1]) + 1
+			}
+			if sum != card {
+				return cr.n, ErrInvalidRoaring
+			}
+		case card > arrayMaxSize:
+			c.kind = bitmapKind
+			c.bitmap = make([]uint64, bitmapWords)
+			if err := binary.Read(cr, binary.LittleEndian, c.bitmap); err != nil {
+				return cr.n, err
+			}
+			if newContainerOfWords(c.bitmap).card != card {
+				return cr.n, ErrInvalidRoaring
+			}
+		default:
+			c.kind = arrayKind
+			c.array = make([]uint16, card)
+			if err := binary.Read(cr, binary.LittleEndian, c.array); err != nil {
+				return cr.n, err
+			}
+			if !slices.IsSorted(c.array) || len(slices.Compact(slices.Clone(c.array))) != card {
+				return cr.n, ErrInvalidRoaring
+			}
+		}
+		cs[i] = c
+	}
+	rb.keys, rb.containers = keys, cs
+	return cr.n, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
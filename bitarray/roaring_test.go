@@ -0,0 +1,163 @@
+package bitarray
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestRoaringAddRemove(t *testing.T) {
+	rb := NewRoaring()
+	assert.True(t, rb.Add(1))
+	assert.False(t, rb.Add(1))
+	assert.True(t, rb.Add(1<<20))
+	assert.True(t, rb.Add(1<<32-1))
+	assert.True(t, rb.Contains(1<<20))
+	assert.False(t, rb.Contains(2))
+	assert.Equal(t, 3, rb.Cardinality())
+	assert.Equal(t, []uint32{1, 1 << 20, 1<<32 - 1}, slices.Collect(rb.Iter()))
+	assert.True(t, rb.Remove(1<<20))
+	assert.False(t, rb.Remove(1<<20))
+	assert.Equal(t, 2, len(rb.keys))
+
+	// grow a container past the array limit and shrink it back
+	for i := range uint32(5000) {
+		rb.Add(i * 2)
+	}
+	assert.Equal(t, bitmapKind, rb.containers[0].kind)
+	for i := range uint32(2000) {
+		rb.Remove(i * 2)
+	}
+	assert.Equal(t, arrayKind, rb.containers[0].kind)
+	assert.Equal(t, 3000+2, rb.Cardinality())
+}
+
+func TestRoaringRunOptimize(t *testing.T) {
+	rb := NewRoaring()
+	for i := range uint32(100000) {
+		rb.Add(i + 10)
+	}
+	rb.Add(200000)
+	rb.RunOptimize()
+	assert.Equal(t, runKind, rb.containers[0].kind)
+	assert.Equal(t, runKind, rb.containers[1].kind)
+	assert.Equal(t, arrayKind, rb.containers[2].kind)
+	assert.True(t, rb.Contains(65535))
+	assert.True(t, rb.Contains(100009))
+	assert.False(t, rb.Contains(100010))
+	assert.Equal(t, 100001, rb.Cardinality())
+	assert.True(t, rb.Remove(50))
+	assert.True(t, rb.Add(50))
+	assert.Equal(t, 100001, rb.Cardinality())
+}
+
+func randomRoaring(n int, spread uint32) (*RoaringBitmap, map[uint32]bool) {
+	rb := NewRoaring()
+	set := make(map[uint32]bool)
+	for range n {
+		v := rand.Uint32N(spread)
+		rb.Add(v)
+		set[v] = true
+	}
+	// a dense stretch to get bitmap and run containers
+	for v := spread; v < spread+70000; v++ {
+		if rand.IntN(4) != 0 {
+			rb.Add(v)
+			set[v] = true
+		}
+	}
+	return rb, set
+}
+
+func TestRoaringAlgebra(t *testing.T) {
+	for range 5 {
+		a, sa := randomRoaring(20000, 1<<18)
+		b, sb := randomRoaring(20000, 1<<18)
+		if rand.IntN(2) == 0 {
+			a.RunOptimize()
+		}
+		check := func(rb *RoaringBitmap, keep func(x uint32) bool) {
+			want := make([]uint32, 0)
+			for v := range uint32(1<<18 + 70000) {
+				if keep(v) {
+					want = append(want, v)
+				}
+			}
+			assert.Equal(t, want, slices.Collect(rb.Iter()))
+			assert.Equal(t, len(want), rb.Cardinality())
+		}
+		and := a.Clone()
+		and.And(b)
+		check(and, func(x uint32) bool { return sa[x] && sb[x] })
+		assert.Equal(t, and.Cardinality(), a.AndCardinality(b))
+		or := a.Clone()
+		or.Or(b)
+		check(or, func(x uint32) bool { return sa[x] || sb[x] })
+		xor := a.Clone()
+		xor.Xor(b)
+		check(xor, func(x uint32) bool { return sa[x] != sb[x] })
+		andNot := a.Clone()
+		andNot.AndNot(b)
+		check(andNot, func(x uint32) bool { return sa[x] && !sb[x] })
+	}
+}
+
+func TestRoaringSerializationFormat(t *testing.T) {
+	rb := NewRoaring()
+	rb.Add(1)
+	rb.Add(2)
+	rb.Add(3)
+	data, err := rb.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{
+		0x3a, 0x30, 0, 0, 1, 0, 0, 0, // cookie without runs, container count
+		0, 0, 2, 0, // key 0, cardinality 3
+		16, 0, 0, 0, // offset
+		1, 0, 2, 0, 3, 0,
+	}, data)
+
+	rb = NewRoaring()
+	for i := range uint32(100) {
+		rb.Add(i + 1)
+	}
+	rb.RunOptimize()
+	data, err = rb.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{
+		0x3b, 0x30, 0, 0, // cookie with runs, one container
+		1,           // run flags
+		0, 0, 99, 0, // key 0, cardinality 100
+		1, 0, 1, 0, 99, 0, // one run from 1 of length 100
+	}, data)
+}
+
+func TestRoaringSerializationRoundTrip(t *testing.T) {
+	a, _ := randomRoaring(50000, 1<<22)
+	for range 2 {
+		buf := new(bytes.Buffer)
+		n, err := a.WriteTo(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(buf.Len()), n)
+		b := NewRoaring()
+		m, err := b.ReadFrom(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, n, m)
+		assert.Equal(t, slices.Collect(a.Iter()), slices.Collect(b.Iter()))
+		a.RunOptimize()
+	}
+	assert.ErrorIs(t, NewRoaring().UnmarshalBinary([]byte{1, 2, 3, 4}), ErrInvalidRoaring)
+	assert.Error(t, NewRoaring().UnmarshalBinary([]byte{0x3a, 0x30, 0, 0, 1}))
+
+	for _, runs := range [][]byte{
+		{10, 0, 4, 0, 1, 0, 4, 0}, // unsorted
+		{1, 0, 4, 0, 3, 0, 4, 0},  // overlapping
+		{1, 0, 4, 0, 6, 0, 4, 0},  // adjacent
+	} {
+		data := append([]byte{0x3b, 0x30, 0, 0, 1, 0, 0, 9, 0, 2, 0}, runs...)
+		assert.ErrorIs(t, NewRoaring().UnmarshalBinary(data), ErrInvalidRoaring)
+	}
+	ok := append([]byte{0x3b, 0x30, 0, 0, 1, 0, 0, 9, 0, 2, 0}, 1, 0, 4, 0, 7, 0, 4, 0)
+	assert.NoError(t, NewRoaring().UnmarshalBinary(ok))
+}
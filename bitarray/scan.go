@@ -0,0 +1,126 @@
+package bitarray
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// NextSet index of the first 1b at or after from, -1 if none
+func (ab *SyncBitArray) NextSet(from int) int {
+	if from < 0 {
+		panic("from must not be negative")
+	}
+	if from >= ab.len {
+		return -1
+	}
+	wIdx := from >> uint64Bit
+	word := ab.data[wIdx].Load() & (^uint64(0) << (from % bitPerUnit))
+	for {
+		if word != 0 {
+			idx := wIdx<<uint64Bit + bits.TrailingZeros64(word)
+			if idx >= ab.len {
+				return -1
+			}
+			return idx
+		}
+		wIdx++
+		if wIdx == len(ab.data) {
+			return -1
+		}
+		word = ab.data[wIdx].Load()
+	}
+}
+
+// NextClear index of the first 0b at or after from, -1 if none
+func (ab *SyncBitArray) NextClear(from int) int {
+	if from < 0 {
+		panic("from must not be negative")
+	}
+	if from >= ab.len {
+		return -1
+	}
+	wIdx := from >> uint64Bit
+	word := ^ab.data[wIdx].Load() & (^uint64(0) << (from % bitPerUnit))
+	for {
+		if word != 0 {
+			idx := wIdx<<uint64Bit + bits.TrailingZeros64(word)
+			if idx >= ab.len {
+				return -1
+			}
+			return idx
+		}
+		wIdx++
+		if wIdx == len(ab.data) {
+			return -1
+		}
+		word = ^ab.data[wIdx].Load()
+	}
+}
+
+// PrevSet index of the last 1b at or before from, -1 if none
+func (ab *SyncBitArray) PrevSet(from int) int {
+	if from < 0 || ab.len == 0 {
+		return -1
+	}
+	if from >= ab.len {
+		from = ab.len - 1
+	}
+	wIdx := from >> uint64Bit
+	word := ab.data[wIdx].Load() & (^uint64(0) >> (bitPerUnit - 1 - from%bitPerUnit))
+	for {
+		if word != 0 {
+			return wIdx<<uint64Bit + bitPerUnit - 1 - bits.LeadingZeros64(word)
+		}
+		wIdx--
+		if wIdx < 0 {
+			return -1
+		}
+		word = ab.data[wIdx].Load()
+	}
+}
+
+// IterSet index of every 1b in ascending order, zero words are skipped as a whole
+func (ab *SyncBitArray) IterSet() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for wIdx := range ab.data {
+			word := ab.data[wIdx].Load()
+			for word != 0 {
+				idx := wIdx<<uint64Bit + bits.TrailingZeros64(word)
+				if idx >= ab.len || !yield(idx) {
+					return
+				}
+				word &= word - 1
+			}
+		}
+	}
+}
+
+// SetNextClear atomically set the first 0b at or after from and return its index, -1 if none
+func (ab *SyncBitArray) SetNextClear(from int) int {
+	if from < 0 {
+		panic("from must not be negative")
+	}
+	if from >= ab.len {
+		return -1
+	}
+	wIdx := from >> uint64Bit
+	low := ^uint64(0) << (from % bitPerUnit)
+	for wIdx < len(ab.data) {
+		old := ab.data[wIdx].Load()
+		free := ^old & low & ab.wordMask(wIdx)
+		if free == 0 {
+			wIdx++
+			low = ^uint64(0)
+			continue
+		}
+		if ab.gate.paused() {
+			continue
+		}
+		bit := free & -free
+		if ab.data[wIdx].CompareAndSwap(old, old|bit) {
+			ab.bitCnt.Add(1)
+			return wIdx<<uint64Bit + bits.TrailingZeros64(bit)
+		}
+	}
+	return -1
+}
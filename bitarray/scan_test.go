@@ -0,0 +1,90 @@
+package bitarray
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestNextPrev(t *testing.T) {
+	ba := New(300)
+	for _, i := range []int{0, 63, 64, 130, 299} {
+		ba.Set(i)
+	}
+	assert.Equal(t, 0, ba.NextSet(0))
+	assert.Equal(t, 63, ba.NextSet(1))
+	assert.Equal(t, 64, ba.NextSet(64))
+	assert.Equal(t, 130, ba.NextSet(65))
+	assert.Equal(t, 299, ba.NextSet(131))
+	assert.Equal(t, -1, ba.NextSet(300))
+	assert.Equal(t, 299, ba.PrevSet(1000))
+	assert.Equal(t, 130, ba.PrevSet(298))
+	assert.Equal(t, 64, ba.PrevSet(129))
+	assert.Equal(t, 63, ba.PrevSet(63))
+	assert.Equal(t, 0, ba.PrevSet(62))
+	assert.Equal(t, -1, ba.PrevSet(-1))
+	assert.Equal(t, 1, ba.NextClear(0))
+	assert.Equal(t, 65, ba.NextClear(63))
+	assert.Equal(t, []int{0, 63, 64, 130, 299}, slices.Collect(ba.IterSet()))
+	ba.Unset(0)
+	assert.Equal(t, -1, ba.PrevSet(62))
+
+	full := New(100)
+	for i := range 100 {
+		full.Set(i)
+	}
+	assert.Equal(t, -1, full.NextClear(0))
+	// bits put beyond the length in the last word are never reported
+	ba = New(10)
+	ba.PutUint64(0, 1<<20)
+	assert.Equal(t, -1, ba.NextSet(0))
+	assert.Empty(t, slices.Collect(ba.IterSet()))
+}
+
+func TestScanEmpty(t *testing.T) {
+	ba := NewFrom(nil)
+	assert.Equal(t, -1, ba.PrevSet(5))
+	assert.Equal(t, -1, ba.NextSet(0))
+	assert.Equal(t, -1, ba.NextClear(0))
+}
+
+func TestIterSetRandom(t *testing.T) {
+	length := 10000
+	ba := New(length)
+	want := make([]int, 0)
+	for i := range length {
+		if rand.IntN(50) == 0 {
+			ba.Set(i)
+			want = append(want, i)
+		}
+	}
+	assert.Equal(t, want, slices.Collect(ba.IterSet()))
+	got := make([]int, 0)
+	for i := ba.NextSet(0); i >= 0; i = ba.NextSet(i + 1) {
+		got = append(got, i)
+	}
+	assert.Equal(t, want, got)
+	got = got[:0]
+	for i := ba.PrevSet(length); i >= 0; i = ba.PrevSet(i - 1) {
+		got = append(got, i)
+	}
+	slices.Reverse(got)
+	assert.Equal(t, want, got)
+}
+
+func TestSetNextClear(t *testing.T) {
+	ba := New(130)
+	for i := range 64 {
+		ba.Set(i)
+	}
+	ba.Set(65)
+	assert.Equal(t, 64, ba.SetNextClear(0))
+	assert.Equal(t, 66, ba.SetNextClear(0))
+	assert.Equal(t, 128, ba.SetNextClear(128))
+	assert.Equal(t, 129, ba.SetNextClear(128))
+	assert.Equal(t, -1, ba.SetNextClear(128))
+	assert.Equal(t, -1, ba.SetNextClear(130))
+	assert.Equal(t, 69, ba.BitCnt())
+	assert.True(t, ba.Get(129))
+}
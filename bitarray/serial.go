@@ -0,0 +1,117 @@
+package bitarray
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"gotools/i64adder"
+	"hash/crc32"
+	"io"
+	"math/bits"
+	"sync/atomic"
+)
+
+const (
+	serialMagic  = 0x42415931 // "BAY1"
+	serialHeader = 12
+	// serialChunk words encoded per write so huge arrays stream through a small buffer
+	serialChunk = 512
+)
+
+var ErrInvalidBitArray = errors.New("invalid bit array encoding")
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// MarshalBinary encode the array keeping its logical length, see WriteTo for the layout
+func (ab *SyncBitArray) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, serialHeader+8*len(ab.data)+4))
+	if _, err := ab.WriteTo(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary replace the array by data produced by MarshalBinary
+func (ab *SyncBitArray) UnmarshalBinary(data []byte) error {
+	_, err := ab.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// WriteTo stream the array to w, the layout in little endian is a header of magic uint32 and
+// length uint64, the words, then the CRC-32C of everything before it. Words are loaded one by one
+// so concurrent writes may or may not be captured.
+func (ab *SyncBitArray) WriteTo(w io.Writer) (int64, error) {
+	crc := crc32.New(castagnoli)
+	mw := io.MultiWriter(w, crc)
+	buf := make([]byte, 0, 8*serialChunk)
+	buf = binary.LittleEndian.AppendUint32(buf, serialMagic)
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(ab.len))
+	var total int64
+	for i := range ab.data {
+		buf = binary.LittleEndian.AppendUint64(buf, ab.data[i].Load()&ab.wordMask(i))
+		if len(buf)+8 > cap(buf) {
+			n, err := mw.Write(buf)
+			total += int64(n)
+			if err != nil {
+				return total, err
+			}
+			buf = buf[:0]
+		}
+	}
+	if len(buf) > 0 {
+		n, err := mw.Write(buf)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	n, err := w.Write(binary.LittleEndian.AppendUint32(nil, crc.Sum32()))
+	return total + int64(n), err
+}
+
+// ReadFrom replace the array by the encoding read from r, the array is left untouched on error
+func (ab *SyncBitArray) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+	crc := crc32.New(castagnoli)
+	tr := io.TeeReader(cr, crc)
+	header := make([]byte, serialHeader)
+	if _, err := io.ReadFull(tr, header); err != nil {
+		return cr.n, err
+	}
+	size := binary.LittleEndian.Uint64(header[4:])
+	if binary.LittleEndian.Uint32(header) != serialMagic || size == 0 || size > 1<<62 {
+		return cr.n, ErrInvalidBitArray
+	}
+	nb := &SyncBitArray{len: int(size), bitCnt: i64adder.New()}
+	words := (nb.len + bitPerUnit - 1) / bitPerUnit
+	var cnt int64
+	buf := make([]byte, 8*serialChunk)
+	for lo := 0; lo < words; lo += serialChunk {
+		hi := min(lo+serialChunk, words)
+		chunk := buf[:8*(hi-lo)]
+		if _, err := io.ReadFull(tr, chunk); err != nil {
+			return cr.n, err
+		}
+		// grow with the input instead of trusting the length up front
+		nb.data = append(nb.data, make([]atomic.Uint64, hi-lo)...)
+		for i := lo; i < hi; i++ {
+			v := binary.LittleEndian.Uint64(chunk[8*(i-lo):])
+			if i == words-1 && nb.len%bitPerUnit != 0 && v>>(nb.len%bitPerUnit) != 0 {
+				return cr.n, ErrInvalidBitArray
+			}
+			nb.data[i].Store(v)
+			cnt += int64(bits.OnesCount64(v))
+		}
+	}
+	sum := crc.Sum32()
+	trailer := make([]byte, 4)
+	if _, err := io.ReadFull(cr, trailer); err != nil {
+		return cr.n, err
+	}
+	if binary.LittleEndian.Uint32(trailer) != sum {
+		return cr.n, ErrInvalidBitArray
+	}
+	nb.bitCnt.Add(cnt)
+	ab.data, ab.bitCnt, ab.len = nb.data, nb.bitCnt, nb.len
+	return cr.n, nil
+}
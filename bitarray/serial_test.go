@@ -0,0 +1,64 @@
+package bitarray
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	ab := New(100)
+	ab.Set(0)
+	ab.Set(63)
+	ab.Set(99)
+	data, err := ab.MarshalBinary()
+	assert.Nil(t, err)
+	assert.Equal(t, serialHeader+2*8+4, len(data))
+
+	var nb SyncBitArray
+	assert.Nil(t, nb.UnmarshalBinary(data))
+	assert.Equal(t, 100, nb.Len())
+	assert.Equal(t, 3, nb.BitCnt())
+	assert.Equal(t, ab.Uint64Array(), nb.Uint64Array())
+	assert.True(t, nb.Set(50))
+	assert.Panics(t, func() {
+		nb.Get(100)
+	})
+}
+
+func TestWriteToReadFrom(t *testing.T) {
+	ab := New(100000)
+	for i := 0; i < ab.Len(); i += 7 {
+		ab.Set(i)
+	}
+	buf := new(bytes.Buffer)
+	n, err := ab.WriteTo(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	nb := New(1)
+	m, err := nb.ReadFrom(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, n, m)
+	assert.Equal(t, ab.Len(), nb.Len())
+	assert.Equal(t, ab.BitCnt(), nb.BitCnt())
+	assert.Equal(t, ab.Uint64Array(), nb.Uint64Array())
+}
+
+func TestReadFromCorrupted(t *testing.T) {
+	ab := New(130)
+	ab.Set(129)
+	data, _ := ab.MarshalBinary()
+
+	nb := New(10)
+	flipped := bytes.Clone(data)
+	flipped[serialHeader] ^= 1
+	assert.ErrorIs(t, nb.UnmarshalBinary(flipped), ErrInvalidBitArray)
+	assert.ErrorIs(t, nb.UnmarshalBinary(data[:len(data)-1]), io.ErrUnexpectedEOF)
+	bad := bytes.Clone(data)
+	bad[0] ^= 1
+	assert.ErrorIs(t, nb.UnmarshalBinary(bad), ErrInvalidBitArray)
+	// the array is untouched on error
+	assert.Equal(t, 10, nb.Len())
+}
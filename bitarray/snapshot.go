@@ -0,0 +1,119 @@
+package bitarray
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// writeGate lets Snapshot copy the words as of one instant. Single word writers only check frozen
+// before their CAS and wait while it is raised: a write that passed the check before the Snapshot
+// started lands either before or after its word is copied, and anything ordered after it starts later
+// and waits. Multi word writers must not be torn by a copy, they register in bulk for their whole run
+// and Snapshot waits for bulk to drain after raising frozen.
+type writeGate struct {
+	frozen atomic.Bool
+	bulk   atomic.Int64
+	mu     sync.Mutex
+}
+
+// paused wait out a running Snapshot, report whether there was one so the caller reloads its word
+func (g *writeGate) paused() bool {
+	if !g.frozen.Load() {
+		return false
+	}
+	for g.frozen.Load() {
+		runtime.Gosched()
+	}
+	return true
+}
+
+// enter start a multi word write
+func (g *writeGate) enter() {
+	for {
+		g.bulk.Add(1)
+		if !g.frozen.Load() {
+			return
+		}
+		g.bulk.Add(-1)
+		g.paused()
+	}
+}
+
+// exit end a multi word write
+func (g *writeGate) exit() {
+	g.bulk.Add(-1)
+}
+
+// freeze run fn while no writer changes the words
+func (g *writeGate) freeze(fn func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.frozen.Store(true)
+	defer g.frozen.Store(false)
+	for g.bulk.Load() != 0 {
+		runtime.Gosched()
+	}
+	fn()
+}
+
+// Snapshot immutable point in time copy of a SyncBitArray
+type Snapshot struct {
+	data []uint64
+	len  int
+	ones int
+}
+
+// Snapshot copy the array as of one instant, writers are held off while the words are copied so the
+// bits and BitCnt of the copy always agree
+func (ab *SyncBitArray) Snapshot() *Snapshot {
+	s := &Snapshot{data: make([]uint64, len(ab.data)), len: ab.len}
+	ab.gate.freeze(func() {
+		for i := range ab.data {
+			s.data[i] = ab.data[i].Load() & ab.wordMask(i)
+		}
+	})
+	for _, w := range s.data {
+		s.ones += bits.OnesCount64(w)
+	}
+	return s
+}
+
+// Len array length
+func (s *Snapshot) Len() int {
+	return s.len
+}
+
+// BitCnt bit 1 count
+func (s *Snapshot) BitCnt() int {
+	return s.ones
+}
+
+// Get return true if given index in bitarray is 1b
+func (s *Snapshot) Get(index int) bool {
+	if index < 0 || index >= s.len {
+		panic(fmt.Sprintf("index %d out of range %d", index, s.len))
+	}
+	return s.data[index>>uint64Bit]&(1<<(index%bitPerUnit)) != 0
+}
+
+// Iter [index,set flag]
+func (s *Snapshot) Iter() iter.Seq2[int, bool] {
+	return func(yield func(int, bool) bool) {
+		for i := range s.len {
+			if !yield(i, s.Get(i)) {
+				break
+			}
+		}
+	}
+}
+
+// Uint64Array copy of the words
+func (s *Snapshot) Uint64Array() []uint64 {
+	ret := make([]uint64, len(s.data))
+	copy(ret, s.data)
+	return ret
+}
@@ -0,0 +1,66 @@
+package bitarray
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	ab := New(100)
+	ab.Set(1)
+	ab.Set(99)
+	s := ab.Snapshot()
+	ab.Set(2)
+	assert.Equal(t, 100, s.Len())
+	assert.Equal(t, 2, s.BitCnt())
+	assert.True(t, s.Get(99))
+	assert.False(t, s.Get(2))
+	assert.Equal(t, []uint64{2, 1 << 35}, s.Uint64Array())
+	var set []int
+	for i, v := range s.Iter() {
+		if v {
+			set = append(set, i)
+		}
+	}
+	assert.Equal(t, []int{1, 99}, set)
+	assert.Panics(t, func() {
+		s.Get(100)
+	})
+}
+
+func TestSnapshotConsistent(t *testing.T) {
+	// every writer flips whole blocks spanning two words, a consistent copy sees no half written block
+	const block = 128
+	ab := New(8 * block)
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lo := w*block + 32
+			for !stop.Load() {
+				ab.SetRange(lo, lo+block/2)
+				ab.ClearRange(lo, lo+block/2)
+			}
+		}()
+	}
+	for range 200 {
+		s := ab.Snapshot()
+		for w := range 8 {
+			var cnt int
+			for i := w*block + 32; i < w*block+32+block/2; i++ {
+				if s.Get(i) {
+					cnt++
+				}
+			}
+			assert.Contains(t, []int{0, block / 2}, cnt)
+		}
+	}
+	stop.Store(true)
+	wg.Wait()
+	assert.Equal(t, 0, ab.Snapshot().BitCnt())
+	assert.Equal(t, 0, ab.BitCnt())
+}
@@ -9,24 +9,61 @@ import (
 	"math"
 )
 
+type bitset interface {
+	bitarray.Bits
+	Uint64Array() []uint64
+}
+
 type BloomFilter struct {
-	bitset *bitarray.SyncBitArray
+	bitset bitset
 	hashes int
 	bitCnt int
+	unsync bool
 }
 
 func New(insertions uint, fpp float64) *BloomFilter {
-	m := int(math.Ceil(-float64(insertions) * math.Log(fpp) / (math.Log(2) * math.Log(2))))
-	k := max(1, math.Ceil(math.Log(2)*float64(m)/float64(insertions)))
-
+	m, k := optimal(insertions, fpp)
 	bf := &BloomFilter{
 		bitset: bitarray.New(m),
-		hashes: int(k),
+		hashes: k,
+		bitCnt: m,
+	}
+	return bf
+}
+
+// NewUnsync bloom filter backed by a plain bitarray.BitArray, faster but only usable by one goroutine at a time
+func NewUnsync(insertions uint, fpp float64) *BloomFilter {
+	m, k := optimal(insertions, fpp)
+	bf := &BloomFilter{
+		bitset: bitarray.NewBitArray(m),
+		hashes: k,
 		bitCnt: m,
+		unsync: true,
 	}
 	return bf
 }
 
+func optimal(insertions uint, fpp float64) (int, int) {
+	m := int(math.Ceil(-float64(insertions) * math.Log(fpp) / (math.Log(2) * math.Log(2))))
+	k := max(1, math.Ceil(math.Log(2)*float64(m)/float64(insertions)))
+	return m, int(k)
+}
+
+func (bf *BloomFilter) newBitset(data []uint64) bitset {
+	if bf.unsync {
+		return bitarray.NewBitArrayFrom(data)
+	}
+	return bitarray.NewFrom(data)
+}
+
+// words copy of the bits, taken as a consistent Snapshot when other goroutines may be adding
+func (bf *BloomFilter) words() []uint64 {
+	if sba, ok := bf.bitset.(*bitarray.SyncBitArray); ok {
+		return sba.Snapshot().Uint64Array()
+	}
+	return bf.bitset.Uint64Array()
+}
+
 func NewWithInsertion(insertions uint) *BloomFilter {
 	return New(insertions, 0.03)
 }
@@ -67,7 +104,7 @@ func (bf *BloomFilter) Marshal() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	arr := bf.bitset.Uint64Array()
+	arr := bf.words()
 	err = binary.Write(buf, binary.LittleEndian, uint64(len(arr)))
 	if err != nil {
 		return nil, err
@@ -111,7 +148,7 @@ func (bf *BloomFilter) Unmarshal(data []byte) error {
 
 	bf.bitCnt = int(m)
 	bf.hashes = int(k)
-	bf.bitset = bitarray.NewFrom(bitsetData)
+	bf.bitset = bf.newBitset(bitsetData)
 	return nil
 }
 
@@ -125,7 +162,7 @@ func (bf *BloomFilter) MarshalJSON() ([]byte, error) {
 	data := jbf{}
 	data.Hashes = bf.hashes
 	data.BitCnt = bf.bitCnt
-	data.Bitset = bf.bitset.Uint64Array()
+	data.Bitset = bf.words()
 	marshal, err := json.Marshal(data)
 	if err != nil {
 		return nil, err
@@ -141,6 +178,6 @@ func (bf *BloomFilter) UnmarshalJSON(bys []byte) error {
 	}
 	bf.bitCnt = data.BitCnt
 	bf.hashes = data.Hashes
-	bf.bitset = bitarray.NewFrom(data.Bitset)
+	bf.bitset = bf.newBitset(data.Bitset)
 	return nil
 }
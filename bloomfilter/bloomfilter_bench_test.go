@@ -46,3 +46,17 @@ func BenchmarkBloomFilter_Contains(b *testing.B) {
 		bf.Contains(data[rand.Intn(1000000)])
 	}
 }
+
+func BenchmarkBloomFilterUnsync_Add(b *testing.B) {
+	bf := NewUnsync(1000000, 0.03)
+	rand.Seed(time.Now().UnixNano())
+	data := make([][]byte, b.N)
+	for i := 0; i < b.N; i++ {
+		data[i] = make([]byte, 16)
+		rand.Read(data[i])
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bf.Add(data[i])
+	}
+}
@@ -118,3 +118,20 @@ func TestJson(t *testing.T) {
 	assert.Equal(t, bf.bitset.Uint64Array(), bfn.bitset.Uint64Array())
 
 }
+
+func TestUnsync(t *testing.T) {
+	bf := NewUnsync(10000, 0.01)
+	for i := 0; i < 10000; i++ {
+		bf.AddString(fmt.Sprintf("ele-%d", i))
+	}
+	for i := 0; i < 10000; i++ {
+		assert.True(t, bf.ContainsString(fmt.Sprintf("ele-%d", i)))
+	}
+	bys, err := bf.Marshal()
+	assert.Nil(t, err)
+	nbf := NewUnsync(1, 0.01)
+	assert.Nil(t, nbf.Unmarshal(bys))
+	assert.True(t, nbf.ContainsString("ele-1"))
+	assert.Equal(t, bf.bitset.Uint64Array(), nbf.bitset.Uint64Array())
+	assert.Equal(t, bf.bitset.BitCnt(), nbf.bitset.BitCnt())
+}
@@ -0,0 +1,103 @@
+package delayqueue
+
+import (
+	"context"
+	"gotools/timewheel"
+	"sync"
+	"time"
+)
+
+// DelayQueue unbounded queue whose items can only be taken once their delay elapsed,
+// items become available in deadline order
+type DelayQueue[T any] struct {
+	tw      *timewheel.TimeWheel
+	mu      sync.Mutex
+	ready   []T
+	head    int
+	pending int
+	notify  chan struct{}
+}
+
+// New create a queue scheduling delays on tw
+func New[T any](tw *timewheel.TimeWheel) *DelayQueue[T] {
+	return &DelayQueue[T]{tw: tw, notify: make(chan struct{}, 1)}
+}
+
+// Put add item which becomes available after delay
+func (q *DelayQueue[T]) Put(item T, delay time.Duration) {
+	if delay <= 0 {
+		q.mu.Lock()
+		q.push(item)
+		q.mu.Unlock()
+		return
+	}
+	q.mu.Lock()
+	q.pending++
+	q.mu.Unlock()
+	q.tw.AfterFunc(delay, func() {
+		q.mu.Lock()
+		q.pending--
+		q.push(item)
+		q.mu.Unlock()
+	})
+}
+
+// Take wait until an expired item is available or ctx done
+func (q *DelayQueue[T]) Take(ctx context.Context) (T, error) {
+	for {
+		if v, ok := q.Poll(); ok {
+			return v, nil
+		}
+		select {
+		case <-q.notify:
+		case <-ctx.Done():
+			return *new(T), ctx.Err()
+		}
+	}
+}
+
+// Poll return an expired item without blocking
+func (q *DelayQueue[T]) Poll() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.head == len(q.ready) {
+		return *new(T), false
+	}
+	v := q.ready[q.head]
+	q.ready[q.head] = *new(T)
+	q.head++
+	if q.head == len(q.ready) {
+		q.ready = q.ready[:0]
+		q.head = 0
+	} else {
+		if q.head > len(q.ready)/2 {
+			// drop the consumed prefix so a queue that never drains does not grow forever
+			n := copy(q.ready, q.ready[q.head:])
+			clear(q.ready[n:])
+			q.ready = q.ready[:n]
+			q.head = 0
+		}
+		// pass the wakeup on to another taker
+		q.signal()
+	}
+	return v, true
+}
+
+// Len count of all items, expired or not
+func (q *DelayQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pending + len(q.ready) - q.head
+}
+
+func (q *DelayQueue[T]) push(item T) {
+	q.ready = append(q.ready, item)
+	q.signal()
+}
+
+func (q *DelayQueue[T]) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,99 @@
+package delayqueue
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gotools/timewheel"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPoll(t *testing.T) {
+	clock := timewheel.NewFakeClock(time.Unix(0, 0))
+	tw := timewheel.New(time.Millisecond, 16, timewheel.WithClock(clock))
+	defer tw.Close()
+	q := New[int](tw)
+	q.Put(3, 30*time.Millisecond)
+	q.Put(1, 10*time.Millisecond)
+	q.Put(0, 0)
+	q.Put(2, 20*time.Millisecond)
+	assert.Equal(t, 4, q.Len())
+	v, ok := q.Poll()
+	assert.True(t, ok)
+	assert.Equal(t, 0, v)
+	_, ok = q.Poll()
+	assert.False(t, ok)
+	clock.Advance(25 * time.Millisecond)
+	assert.Equal(t, 3, q.Len())
+	for i := 1; i <= 2; i++ {
+		v, ok = q.Poll()
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+	_, ok = q.Poll()
+	assert.False(t, ok)
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestTake(t *testing.T) {
+	tw := timewheel.New(time.Millisecond, 16)
+	defer tw.Close()
+	q := New[int](tw)
+	size := 100
+	begin := time.Now()
+	for i := range size {
+		q.Put(i, 20*time.Millisecond)
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	res := make(map[int]bool)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				v, err := q.Take(ctx)
+				cancel()
+				if err != nil {
+					return
+				}
+				assert.GreaterOrEqual(t, time.Since(begin), 20*time.Millisecond)
+				mu.Lock()
+				res[v] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, size, len(res))
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestTakeCancel(t *testing.T) {
+	tw := timewheel.New(time.Millisecond, 16)
+	defer tw.Close()
+	q := New[int](tw)
+	q.Put(1, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := q.Take(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestPollCompacts(t *testing.T) {
+	tw := timewheel.New(time.Millisecond, 16, timewheel.WithClock(timewheel.NewFakeClock(time.Unix(0, 0))))
+	defer tw.Close()
+	q := New[int](tw)
+	q.Put(-1, 0)
+	// one item always waits, the queue never drains completely
+	for i := range 100000 {
+		q.Put(i, 0)
+		v, ok := q.Poll()
+		assert.True(t, ok)
+		assert.Equal(t, i-1, v)
+	}
+	assert.Equal(t, 1, q.Len())
+	assert.Less(t, cap(q.ready), 16)
+}
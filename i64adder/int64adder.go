@@ -2,6 +2,7 @@ package i64adder
 
 import (
 	"golang.org/x/sys/cpu"
+	"gotools/internal/mhash"
 	"sync/atomic"
 )
 
@@ -25,7 +26,7 @@ func New() *Adder {
 	return c
 }
 func (addr *Adder) Add(x int64) {
-	idx := hash() & (adderChunkSize - 1)
+	idx := mhash.Hash() & (adderChunkSize - 1)
 	atomic.AddInt64(&addr.cells[idx].n, x)
 }
 func (addr *Adder) Incr() {
@@ -36,8 +37,8 @@ func (addr *Adder) Decr() {
 }
 func (addr *Adder) Sum() int64 {
 	sum := int64(0)
-	for _, n := range addr.cells {
-		sum += n.n
+	for i := range addr.cells {
+		sum += atomic.LoadInt64(&addr.cells[i].n)
 	}
 	return sum
 }
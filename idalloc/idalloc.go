@@ -0,0 +1,92 @@
+package idalloc
+
+import (
+	"fmt"
+	"golang.org/x/sys/cpu"
+	"gotools/bitarray"
+	"gotools/internal/mhash"
+	"sync/atomic"
+)
+
+const hintShards = 16
+
+type hint struct {
+	_    cpu.CacheLinePad
+	from atomic.Int64
+	_    cpu.CacheLinePad
+}
+
+// Allocator lock free allocator of the integer IDs in [0, size), a set bit marks an ID in use.
+// Each M searches from its own hint, a lower bound of the free IDs kept on a separate cache line, so
+// Acquire skips the used prefix without every caller writing one shared word. Without racing
+// Acquire and Release the lowest free ID is handed out.
+type Allocator struct {
+	bits  *bitarray.SyncBitArray
+	hints []hint
+	_     cpu.CacheLinePad
+	// releases bumped by every Release, an Acquire that saw it move during its scan may have passed
+	// over a released ID and must not keep the hint it raised
+	releases atomic.Int64
+	_        cpu.CacheLinePad
+	// afterScan test hook called by Acquire between its scan and the hint update
+	afterScan func()
+}
+
+func New(size int) *Allocator {
+	return &Allocator{bits: bitarray.New(size), hints: make([]hint, hintShards)}
+}
+
+// Acquire take the lowest free ID, false if all IDs are in use
+func (a *Allocator) Acquire() (int, bool) {
+	h := &a.hints[mhash.Hash()&(hintShards-1)]
+	epoch := a.releases.Load()
+	from := h.from.Load()
+	id := a.bits.SetNextClear(int(from))
+	if id < 0 && from > 0 {
+		// a Release racing with the hint update may have left a free ID below the hint
+		id = a.bits.SetNextClear(0)
+	}
+	if id < 0 {
+		return -1, false
+	}
+	if a.afterScan != nil {
+		a.afterScan()
+	}
+	// lost CAS means a Release lowered the hint meanwhile, keep the lower one
+	if h.from.CompareAndSwap(from, int64(id)+1) && a.releases.Load() != epoch {
+		lower(&h.from, from)
+	}
+	return id, true
+}
+
+// Release return id to the allocator, panic if it is not in use
+func (a *Allocator) Release(id int) {
+	if !a.bits.Unset(id) {
+		panic(fmt.Sprintf("id %d is not acquired", id))
+	}
+	// bump before lowering, an Acquire either sees the bump or has its hint lowered afterwards
+	a.releases.Add(1)
+	for i := range a.hints {
+		lower(&a.hints[i].from, int64(id))
+	}
+}
+
+// lower set h to v if v is lower
+func lower(h *atomic.Int64, v int64) {
+	for {
+		from := h.Load()
+		if from <= v || h.CompareAndSwap(from, v) {
+			return
+		}
+	}
+}
+
+// InUse count of acquired IDs
+func (a *Allocator) InUse() int {
+	return a.bits.BitCnt()
+}
+
+// Size count of IDs managed
+func (a *Allocator) Size() int {
+	return a.bits.Len()
+}
@@ -0,0 +1,38 @@
+package idalloc
+
+import (
+	"sync"
+	"testing"
+)
+
+func BenchmarkAcquireRelease(b *testing.B) {
+	a := New(1 << 16)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			id, ok := a.Acquire()
+			if ok {
+				a.Release(id)
+			}
+		}
+	})
+}
+
+func BenchmarkMutexAcquireRelease(b *testing.B) {
+	var mu sync.Mutex
+	used := make([]bool, 1<<16)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mu.Lock()
+			for i := range used {
+				if !used[i] {
+					used[i] = true
+					mu.Unlock()
+					mu.Lock()
+					used[i] = false
+					break
+				}
+			}
+			mu.Unlock()
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package idalloc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestAcquireLowest(t *testing.T) {
+	a := New(130)
+	for i := range 130 {
+		id, ok := a.Acquire()
+		assert.True(t, ok)
+		assert.Equal(t, i, id)
+	}
+	_, ok := a.Acquire()
+	assert.False(t, ok)
+	a.Release(70)
+	a.Release(3)
+	id, _ := a.Acquire()
+	assert.Equal(t, 3, id)
+	id, _ = a.Acquire()
+	assert.Equal(t, 70, id)
+	assert.Equal(t, 130, a.InUse())
+	assert.Panics(t, func() {
+		a.Release(200)
+	})
+	a.Release(5)
+	assert.Panics(t, func() {
+		a.Release(5)
+	})
+}
+
+func TestConcurrentUnique(t *testing.T) {
+	a := New(1000)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := make(map[int]int)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 10000 {
+				id, ok := a.Acquire()
+				if !ok {
+					continue
+				}
+				mu.Lock()
+				seen[id]++
+				assert.Equal(t, 1, seen[id])
+				mu.Unlock()
+				if i%2 == 0 {
+					mu.Lock()
+					seen[id]--
+					mu.Unlock()
+					a.Release(id)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	held := 0
+	for _, n := range seen {
+		held += n
+	}
+	assert.Equal(t, held, a.InUse())
+}
+
+func TestReleaseDuringScan(t *testing.T) {
+	// keep the goroutine on one M so every Acquire uses the same hint
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	a := New(200)
+	for range 100 {
+		a.Acquire()
+	}
+	// a hint below the used prefix is still a valid lower bound, the next scan passes over 5
+	for i := range a.hints {
+		a.hints[i].from.Store(0)
+	}
+	a.afterScan = func() {
+		a.afterScan = nil
+		a.Release(5)
+	}
+	id, _ := a.Acquire()
+	assert.Equal(t, 100, id)
+	id, _ = a.Acquire()
+	assert.Equal(t, 5, id)
+}
@@ -1,4 +1,4 @@
-package i64adder
+package mhash
 
 import (
 	"unsafe"
@@ -11,11 +11,9 @@ func getm() uintptr
 //go:linkname memhash runtime.memhash
 func memhash(p unsafe.Pointer, h, s uintptr) uintptr
 
-//go:noescape
-//go:linkname memhash32 runtime.memhash32
-func memhash32(p unsafe.Pointer, h uintptr) uintptr
-
-func hash() uint64 {
+// Hash of the current M, stable for a goroutine as long as it is not migrated, used to pick a
+// shard so goroutines running on different threads touch different cache lines
+func Hash() uint64 {
 	m := getm()
 	return uint64(memhash(unsafe.Pointer(&m), 0, unsafe.Sizeof(m)))
 }
@@ -0,0 +1,116 @@
+package timewheel
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock time source driving a TimeWheel
+type Clock interface {
+	Now() time.Time
+	// Tick call fn every d until the returned stop func is called
+	Tick(d time.Duration, fn func()) (stop func())
+}
+
+type realClock struct{}
+
+// RealClock clock backed by the runtime timers, fn of Tick runs on a dedicated goroutine
+var RealClock Clock = realClock{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Tick(d time.Duration, fn func()) func() {
+	quit := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fn()
+			case <-quit:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(quit)
+		})
+	}
+}
+
+type fakeTicker struct {
+	d    time.Duration
+	next time.Time
+	fn   func()
+}
+
+// FakeClock manual clock for tests, time only moves when Advance is called and every tick
+// falling into the advanced span runs synchronously on the caller in time order
+type FakeClock struct {
+	mu      sync.Mutex
+	advance sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Tick(d time.Duration, fn func()) func() {
+	if d <= 0 {
+		panic("tick duration must be greater than zero")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{d: d, next: c.now.Add(d), fn: fn}
+	c.tickers = append(c.tickers, t)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, v := range c.tickers {
+			if v == t {
+				c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Advance move the clock forward by d, must not be called from a tick callback
+func (c *FakeClock) Advance(d time.Duration) {
+	c.advance.Lock()
+	defer c.advance.Unlock()
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+	for {
+		c.mu.Lock()
+		var due *fakeTicker
+		for _, t := range c.tickers {
+			if !t.next.After(target) && (due == nil || t.next.Before(due.next)) {
+				due = t
+			}
+		}
+		if due == nil {
+			c.now = target
+			c.mu.Unlock()
+			return
+		}
+		c.now = due.next
+		due.next = due.next.Add(due.d)
+		c.mu.Unlock()
+		due.fn()
+	}
+}
@@ -0,0 +1,73 @@
+package timewheel
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFakeClockFireInOrder(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 4, WithClock(clock))
+	defer tw.Close()
+	fired := make([]int, 0)
+	for _, d := range []int{50, 3, 17, 3, 120, 1} {
+		tw.AfterFunc(time.Duration(d)*time.Millisecond, func() {
+			fired = append(fired, d)
+		})
+	}
+	clock.Advance(2 * time.Millisecond)
+	assert.Equal(t, []int{1}, fired)
+	clock.Advance(48 * time.Millisecond)
+	assert.Equal(t, []int{1, 3, 3, 17, 50}, fired)
+	clock.Advance(time.Second)
+	assert.Equal(t, []int{1, 3, 3, 17, 50, 120}, fired)
+}
+
+func TestFakeClockNeverEarly(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(10*time.Millisecond, 8, WithClock(clock))
+	defer tw.Close()
+	clock.Advance(3 * time.Millisecond)
+	var at time.Time
+	tw.AfterFunc(25*time.Millisecond, func() {
+		at = clock.Now()
+	})
+	clock.Advance(20 * time.Millisecond)
+	assert.True(t, at.IsZero())
+	clock.Advance(20 * time.Millisecond)
+	assert.Equal(t, time.Unix(0, 0).Add(30*time.Millisecond), at)
+}
+
+func TestFakeClockStopTick(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	var cnt int
+	stop := clock.Tick(time.Second, func() {
+		cnt++
+	})
+	clock.Advance(3500 * time.Millisecond)
+	assert.Equal(t, 3, cnt)
+	stop()
+	clock.Advance(time.Minute)
+	assert.Equal(t, 3, cnt)
+	assert.Equal(t, time.Unix(63, 5e8), clock.Now())
+}
+
+func TestHugeDurationNeverFiresEarly(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 8, WithClock(clock))
+	defer tw.Close()
+	var fired bool
+	tm := tw.AfterFunc(math.MaxInt64, func() {
+		fired = true
+	})
+	clock.Advance(time.Second)
+	assert.False(t, fired)
+	assert.Equal(t, int64(math.MaxInt64), tm.when)
+	tw.AfterFunc(math.MaxInt64/2, func() {})
+	clock.Advance(time.Second)
+	assert.False(t, fired)
+	assert.Equal(t, 2, tw.pending())
+	assert.True(t, tm.Stop())
+}
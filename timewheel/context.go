@@ -0,0 +1,193 @@
+package timewheel
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultTick  = 10 * time.Millisecond
+	defaultSlots = 256
+)
+
+var defaultWheel struct {
+	once sync.Once
+	tw   *TimeWheel
+}
+
+// Default shared wheel with a 10ms tick used by the package level context helpers
+func Default() *TimeWheel {
+	defaultWheel.once.Do(func() {
+		defaultWheel.tw = New(defaultTick, defaultSlots)
+	})
+	return defaultWheel.tw
+}
+
+// WithTimeout like context.WithTimeout but expired by the Default wheel instead of a runtime timer
+func WithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	return Default().WithTimeoutCause(parent, d, nil)
+}
+
+// WithTimeoutCause like context.WithTimeoutCause but expired by the Default wheel
+func WithTimeoutCause(parent context.Context, d time.Duration, cause error) (context.Context, context.CancelFunc) {
+	return Default().WithTimeoutCause(parent, d, cause)
+}
+
+// WithDeadline like context.WithDeadline but expired by the Default wheel
+func WithDeadline(parent context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	return Default().WithDeadlineCause(parent, deadline, nil)
+}
+
+// WithTimeout context cancelled by tw once d elapsed
+func (tw *TimeWheel) WithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	return tw.WithTimeoutCause(parent, d, nil)
+}
+
+// WithTimeoutCause context cancelled by tw once d elapsed, with cause reported by context.Cause
+func (tw *TimeWheel) WithTimeoutCause(parent context.Context, d time.Duration, cause error) (context.Context, context.CancelFunc) {
+	return tw.WithDeadlineCause(parent, tw.clock.Now().Add(d), cause)
+}
+
+// WithDeadline context cancelled by tw at deadline
+func (tw *TimeWheel) WithDeadline(parent context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	return tw.WithDeadlineCause(parent, deadline, nil)
+}
+
+// WithDeadlineCause context cancelled by tw at deadline, Err reports context.DeadlineExceeded and
+// context.Cause reports cause, or DeadlineExceeded if cause is nil. The deadline fires on a tick
+// so it may be late by up to one tick of the wheel.
+func (tw *TimeWheel) WithDeadlineCause(parent context.Context, deadline time.Time, cause error) (context.Context, context.CancelFunc) {
+	if parent == nil {
+		panic("cannot create context from nil parent")
+	}
+	if cur, ok := parent.Deadline(); ok && !cur.After(deadline) {
+		return context.WithCancel(parent)
+	}
+	c := &timerCtx{Context: parent, deadline: deadline, done: make(chan struct{})}
+	cancel := func() {
+		c.cancel(context.Canceled, nil)
+	}
+	if parent.Err() != nil {
+		c.cancel(parent.Err(), context.Cause(parent))
+		return c, cancel
+	}
+	d := deadline.Sub(tw.clock.Now())
+	if d <= 0 {
+		c.cancel(context.DeadlineExceeded, cause)
+		return c, cancel
+	}
+	c.mu.Lock()
+	if parent.Done() != nil {
+		c.stopParent = context.AfterFunc(parent, func() {
+			c.cancel(parent.Err(), context.Cause(parent))
+		})
+	}
+	c.timer = tw.AfterFunc(d, func() {
+		c.cancel(context.DeadlineExceeded, cause)
+	})
+	c.mu.Unlock()
+	return c, cancel
+}
+
+type afterFunc struct {
+	fn func()
+}
+
+// timerCtx context whose deadline is driven by a wheel timer. It implements the AfterFunc method
+// so derived contexts register on it instead of spawning a goroutine. Once done it answers
+// context.Cause through causeCtx, a cancelled standard context carrying the cause.
+type timerCtx struct {
+	context.Context
+	causeCtx   atomic.Pointer[context.Context]
+	deadline   time.Time
+	done       chan struct{}
+	mu         sync.Mutex
+	err        error
+	timer      *Timer
+	stopParent func() bool
+	afters     map[*afterFunc]struct{}
+}
+
+func (c *timerCtx) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *timerCtx) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *timerCtx) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *timerCtx) Value(key any) any {
+	if cc := c.causeCtx.Load(); cc != nil {
+		if v := (*cc).Value(key); v != nil {
+			return v
+		}
+	}
+	return c.Context.Value(key)
+}
+
+// AfterFunc run f in its own goroutine once c is done, see context.AfterFunc
+func (c *timerCtx) AfterFunc(f func()) func() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		go f()
+		return func() bool {
+			return false
+		}
+	}
+	a := &afterFunc{fn: f}
+	if c.afters == nil {
+		c.afters = make(map[*afterFunc]struct{})
+	}
+	c.afters[a] = struct{}{}
+	return func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if _, ok := c.afters[a]; !ok {
+			return false
+		}
+		delete(c.afters, a)
+		return true
+	}
+}
+
+func (c *timerCtx) String() string {
+	return "timewheel.WithDeadline(" + c.deadline.String() + ")"
+}
+
+func (c *timerCtx) cancel(err, cause error) {
+	if cause == nil {
+		cause = err
+	}
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.err = err
+	cc, setCause := context.WithCancelCause(context.Background())
+	setCause(cause)
+	c.causeCtx.Store(&cc)
+	close(c.done)
+	afters := c.afters
+	c.afters = nil
+	timer, stopParent := c.timer, c.stopParent
+	c.mu.Unlock()
+	if timer != nil {
+		timer.Stop()
+	}
+	if stopParent != nil {
+		stopParent()
+	}
+	for a := range afters {
+		go a.fn()
+	}
+}
@@ -0,0 +1,94 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestContextTimeout(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 16, WithClock(clock))
+	defer tw.Close()
+	ctx, cancel := tw.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	child, childCancel := context.WithCancel(ctx)
+	defer childCancel()
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, time.Unix(0, 0).Add(10*time.Millisecond), deadline)
+	clock.Advance(9 * time.Millisecond)
+	assert.Nil(t, ctx.Err())
+	clock.Advance(time.Millisecond)
+	<-ctx.Done()
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+	assert.ErrorIs(t, context.Cause(ctx), context.DeadlineExceeded)
+	<-child.Done()
+	assert.ErrorIs(t, child.Err(), context.DeadlineExceeded)
+}
+
+func TestContextCause(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 16, WithClock(clock))
+	defer tw.Close()
+	errSlow := errors.New("slow backend")
+	ctx, cancel := tw.WithTimeoutCause(context.Background(), 10*time.Millisecond, errSlow)
+	defer cancel()
+	assert.Nil(t, context.Cause(ctx))
+	clock.Advance(10 * time.Millisecond)
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+	assert.ErrorIs(t, context.Cause(ctx), errSlow)
+}
+
+func TestContextParentCancel(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 16, WithClock(clock))
+	defer tw.Close()
+	errShutdown := errors.New("shutdown")
+	parent, parentCancel := context.WithCancelCause(context.WithValue(context.Background(), "k", "v"))
+	ctx, cancel := tw.WithTimeout(parent, time.Hour)
+	defer cancel()
+	assert.Equal(t, "v", ctx.Value("k"))
+	parentCancel(errShutdown)
+	<-ctx.Done()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.ErrorIs(t, context.Cause(ctx), errShutdown)
+	assert.Equal(t, 0, tw.pending())
+
+	ctx, cancel = tw.WithTimeout(parent, time.Hour)
+	defer cancel()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestContextCancel(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 16, WithClock(clock))
+	defer tw.Close()
+	ctx, cancel := tw.WithTimeout(context.Background(), time.Hour)
+	stopped := make(chan struct{})
+	context.AfterFunc(ctx, func() {
+		close(stopped)
+	})
+	cancel()
+	<-stopped
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.Equal(t, 0, tw.pending())
+
+	parent, parentCancel := context.WithDeadline(context.Background(), clock.Now().Add(time.Minute))
+	defer parentCancel()
+	ctx, cancel = tw.WithTimeout(parent, time.Hour)
+	defer cancel()
+	_, isTimer := ctx.(*timerCtx)
+	assert.False(t, isTimer)
+}
+
+func TestDefaultWithTimeout(t *testing.T) {
+	begin := time.Now()
+	ctx, cancel := WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	assert.GreaterOrEqual(t, time.Since(begin), 30*time.Millisecond)
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+}
@@ -0,0 +1,36 @@
+package timewheel
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	base := time.Date(2024, 2, 28, 23, 58, 10, 0, time.UTC)
+	for _, c := range []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 2, 28, 23, 59, 0, 0, time.UTC)},
+		{"*/5 * * * *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"30 9 * * mon-fri", time.Date(2024, 2, 29, 9, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 feb *", time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"15,45 1-3/2 * * 7", time.Date(2024, 3, 3, 1, 15, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		sched, err := parseCron(c.expr)
+		assert.NoError(t, err, c.expr)
+		assert.Equal(t, c.want, sched.next(base), c.expr)
+	}
+}
+
+func TestCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		_, err := parseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
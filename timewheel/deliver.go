@@ -0,0 +1,21 @@
+package timewheel
+
+import (
+	"gotools/mpsc"
+	"gotools/unbounded"
+	"time"
+)
+
+// AfterChan offer v into ch once d elapsed, the consumer must keep ch open while timers are pending
+func AfterChan[T any](tw *TimeWheel, d time.Duration, v T, ch *unbounded.Chan[T]) *Timer {
+	return tw.AfterFunc(d, func() {
+		ch.Offer(v)
+	})
+}
+
+// AfterMPSC add v into q once d elapsed, q must be polled by a single consumer
+func AfterMPSC[T any](tw *TimeWheel, d time.Duration, v T, q *mpsc.MPSC[T]) *Timer {
+	return tw.AfterFunc(d, func() {
+		q.Add(v)
+	})
+}
@@ -0,0 +1,39 @@
+package timewheel
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gotools/mpsc"
+	"gotools/unbounded"
+	"testing"
+	"time"
+)
+
+func TestAfterChan(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 8, WithClock(clock))
+	defer tw.Close()
+	ch := unbounded.New[string]()
+	defer ch.Close()
+	AfterChan(tw, 20*time.Millisecond, "b", ch)
+	AfterChan(tw, 10*time.Millisecond, "a", ch)
+	AfterChan(tw, 30*time.Millisecond, "c", ch).Stop()
+	clock.Advance(time.Second)
+	assert.Equal(t, "a", ch.Poll())
+	assert.Equal(t, "b", ch.Poll())
+}
+
+func TestAfterMPSC(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 8, WithClock(clock))
+	defer tw.Close()
+	q := mpsc.New[int]()
+	for i := 100; i > 0; i-- {
+		AfterMPSC(tw, time.Duration(i)*time.Millisecond, i, q)
+	}
+	clock.Advance(50 * time.Millisecond)
+	assert.Equal(t, 50, q.Len())
+	for i := 1; i <= 50; i++ {
+		assert.Equal(t, i, *q.Poll())
+	}
+	assert.Nil(t, q.Poll())
+}
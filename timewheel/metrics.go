@@ -0,0 +1,120 @@
+package timewheel
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBuckets bucket i counts the latencies below 2^i microseconds, the last one everything above
+const latencyBuckets = 24
+
+type latencyHistogram struct {
+	counts [latencyBuckets]atomic.Int64
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	us := uint64(max(d, 0) / time.Microsecond)
+	idx := min(bits.Len64(us), latencyBuckets-1)
+	h.counts[idx].Add(1)
+}
+
+// LatencyHistogram distribution of the delay between a timer deadline and the moment it fired,
+// Counts[i] is the count of latencies below Bounds[i], the last bucket has no upper bound
+type LatencyHistogram struct {
+	Bounds []time.Duration
+	Counts []int64
+}
+
+// Total count of observed latencies
+func (h LatencyHistogram) Total() int64 {
+	var total int64
+	for _, c := range h.Counts {
+		total += c
+	}
+	return total
+}
+
+// Quantile upper bound of the bucket holding the q quantile, q in [0,1]
+func (h LatencyHistogram) Quantile(q float64) time.Duration {
+	total := h.Total()
+	if total == 0 {
+		return 0
+	}
+	rank := int64(q * float64(total))
+	var seen int64
+	for i, c := range h.Counts {
+		seen += c
+		if seen > rank {
+			return h.Bounds[i]
+		}
+	}
+	return h.Bounds[len(h.Bounds)-1]
+}
+
+func (h *latencyHistogram) snapshot() LatencyHistogram {
+	lh := LatencyHistogram{Bounds: make([]time.Duration, latencyBuckets), Counts: make([]int64, latencyBuckets)}
+	for i := range h.counts {
+		lh.Bounds[i] = time.Duration(1<<i) * time.Microsecond
+		lh.Counts[i] = h.counts[i].Load()
+	}
+	return lh
+}
+
+// Stats health of a wheel
+type Stats struct {
+	// Pending timers waiting to fire
+	Pending int64
+	// Fired timers whose callback was dispatched
+	Fired int64
+	// Cancelled timers stopped before firing
+	Cancelled int64
+	// TickLag how late the wheel processed the oldest due tick on its last advance
+	TickLag time.Duration
+	// MaxTickLag largest TickLag observed
+	MaxTickLag time.Duration
+	// FireLatency delay between deadlines and dispatch of the fired timers
+	FireLatency LatencyHistogram
+}
+
+// Stats current counters of the wheel
+func (tw *TimeWheel) Stats() Stats {
+	return Stats{
+		Pending:     tw.pendingCnt.Sum(),
+		Fired:       tw.firedCnt.Sum(),
+		Cancelled:   tw.cancelledCnt.Sum(),
+		TickLag:     time.Duration(tw.tickLag.Load()),
+		MaxTickLag:  time.Duration(tw.maxTickLag.Load()),
+		FireLatency: tw.latency.snapshot(),
+	}
+}
+
+// Stats counters summed over all the shards, TickLag and MaxTickLag are the worst of the shards
+func (s *Sharded) Stats() Stats {
+	var st Stats
+	for _, tw := range s.shards {
+		one := tw.Stats()
+		st.Pending += one.Pending
+		st.Fired += one.Fired
+		st.Cancelled += one.Cancelled
+		st.TickLag = max(st.TickLag, one.TickLag)
+		st.MaxTickLag = max(st.MaxTickLag, one.MaxTickLag)
+		if st.FireLatency.Counts == nil {
+			st.FireLatency = one.FireLatency
+			continue
+		}
+		for i, c := range one.FireLatency.Counts {
+			st.FireLatency.Counts[i] += c
+		}
+	}
+	return st
+}
+
+// observeLag record the lag of the first due tick when the wheel advances to now
+func (tw *TimeWheel) observeLag(now int64) {
+	lag := max(now-(tw.cur+1)*tw.tick.Nanoseconds(), 0)
+	tw.tickLag.Store(lag)
+	if lag > tw.maxTickLag.Load() {
+		tw.maxTickLag.Store(lag)
+	}
+}
@@ -0,0 +1,47 @@
+package timewheel
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 16, WithClock(clock))
+	defer tw.Close()
+	timers := make([]*Timer, 0)
+	for i := range 10 {
+		timers = append(timers, tw.AfterFunc(time.Duration(i+1)*time.Millisecond, func() {}))
+	}
+	timers[9].Stop()
+	timers[8].Reset(time.Hour)
+	st := tw.Stats()
+	assert.Equal(t, int64(9), st.Pending)
+	assert.Equal(t, int64(1), st.Cancelled)
+	clock.Advance(20 * time.Millisecond)
+	timers[0].Reset(time.Millisecond)
+	st = tw.Stats()
+	assert.Equal(t, int64(2), st.Pending)
+	assert.Equal(t, int64(8), st.Fired)
+	assert.Equal(t, int64(8), st.FireLatency.Total())
+	assert.Equal(t, time.Microsecond, st.FireLatency.Quantile(0.99))
+	assert.Equal(t, time.Duration(0), st.TickLag)
+}
+
+func TestTickLag(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 16, WithClock(clock))
+	defer tw.Close()
+	// drive the wheel every 10ms only, it is always 9ms behind its first due tick
+	tw.stop()
+	tw.stop = clock.Tick(10*time.Millisecond, func() {
+		tw.advance(tw.elapsed())
+	})
+	tw.AfterFunc(time.Millisecond, func() {})
+	clock.Advance(10 * time.Millisecond)
+	st := tw.Stats()
+	assert.Equal(t, 9*time.Millisecond, st.TickLag)
+	assert.Equal(t, 9*time.Millisecond, st.MaxTickLag)
+	assert.Equal(t, 16384*time.Microsecond, st.FireLatency.Quantile(0.5))
+}
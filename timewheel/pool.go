@@ -0,0 +1,134 @@
+package timewheel
+
+import (
+	"gotools/i64adder"
+	"sync"
+)
+
+// Executor run timer callbacks away from the clock tick goroutine
+type Executor interface {
+	Execute(fn func())
+}
+
+// WithExecutor hand every fired callback to e instead of running it on the tick goroutine
+func WithExecutor(e Executor) Option {
+	return func(tw *TimeWheel) {
+		tw.executor = e
+	}
+}
+
+// Pool bounded worker pool, a callback is dropped rather than blocking the wheel when the queue is full
+// and a panicking callback is recovered and reported to the panic hook
+type Pool struct {
+	tasks   chan func()
+	onPanic func(r any)
+	queued  *i64adder.Adder
+	running *i64adder.Adder
+	dropped *i64adder.Adder
+	quit    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+	// mu keeps Execute from queueing once Close started draining
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewPool create a pool running at most workers callbacks at once with queueSize waiting ones,
+// onPanic may be nil to silently recover panics
+func NewPool(workers, queueSize int, onPanic func(r any)) *Pool {
+	if workers <= 0 {
+		panic("workers must be greater than zero")
+	}
+	if queueSize < 0 {
+		panic("queue size must not be negative")
+	}
+	p := &Pool{
+		tasks:   make(chan func(), queueSize),
+		onPanic: onPanic,
+		queued:  i64adder.New(),
+		running: i64adder.New(),
+		dropped: i64adder.New(),
+		quit:    make(chan struct{}),
+	}
+	p.wg.Add(workers)
+	for range workers {
+		go p.work()
+	}
+	return p
+}
+
+// Execute queue fn, never blocks
+func (p *Pool) Execute(fn func()) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		p.dropped.Incr()
+		return
+	}
+	p.queued.Incr()
+	select {
+	case p.tasks <- fn:
+	default:
+		p.queued.Decr()
+		p.dropped.Incr()
+	}
+}
+
+// Queued callbacks waiting for a worker
+func (p *Pool) Queued() int64 {
+	return p.queued.Sum()
+}
+
+// Running callbacks being executed
+func (p *Pool) Running() int64 {
+	return p.running.Sum()
+}
+
+// Dropped callbacks rejected because the queue was full or the pool closed
+func (p *Pool) Dropped() int64 {
+	return p.dropped.Sum()
+}
+
+// Close stop the workers once their current callback returns, callbacks still queued are dropped
+func (p *Pool) Close() {
+	p.once.Do(func() {
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+		close(p.quit)
+		p.wg.Wait()
+		for {
+			select {
+			case <-p.tasks:
+				p.queued.Decr()
+				p.dropped.Incr()
+			default:
+				return
+			}
+		}
+	})
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for {
+		select {
+		case fn := <-p.tasks:
+			p.queued.Decr()
+			p.run(fn)
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+func (p *Pool) run(fn func()) {
+	p.running.Incr()
+	defer func() {
+		p.running.Decr()
+		if r := recover(); r != nil && p.onPanic != nil {
+			p.onPanic(r)
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,90 @@
+package timewheel
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolPanic(t *testing.T) {
+	var panics atomic.Int32
+	pool := NewPool(2, 16, func(r any) {
+		assert.Equal(t, "boom", r)
+		panics.Add(1)
+	})
+	defer pool.Close()
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 8, WithClock(clock), WithExecutor(pool))
+	defer tw.Close()
+	var wg sync.WaitGroup
+	var fired atomic.Int32
+	wg.Add(4)
+	for i := range 4 {
+		tw.AfterFunc(time.Duration(i+1)*time.Millisecond, func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				panic("boom")
+			}
+			fired.Add(1)
+		})
+	}
+	clock.Advance(10 * time.Millisecond)
+	wg.Wait()
+	assert.Equal(t, int32(2), fired.Load())
+	assert.Eventually(t, func() bool {
+		return panics.Load() == 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestPoolSlowCallback(t *testing.T) {
+	pool := NewPool(1, 1, nil)
+	defer pool.Close()
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 8, WithClock(clock), WithExecutor(pool))
+	defer tw.Close()
+	block := make(chan struct{})
+	started := make(chan struct{})
+	tw.AfterFunc(time.Millisecond, func() {
+		close(started)
+		<-block
+	})
+	clock.Advance(time.Millisecond)
+	<-started
+	var fired atomic.Int32
+	for i := range 3 {
+		tw.AfterFunc(time.Duration(i+1)*time.Millisecond, func() {
+			fired.Add(1)
+		})
+	}
+	// the wheel keeps ticking while the only worker is stuck
+	clock.Advance(10 * time.Millisecond)
+	assert.Equal(t, int64(1), pool.Running())
+	assert.Equal(t, int64(1), pool.Queued())
+	assert.Equal(t, int64(2), pool.Dropped())
+	close(block)
+	assert.Eventually(t, func() bool {
+		return fired.Load() == 1 && pool.Running() == 0 && pool.Queued() == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestPoolCloseRacingExecute(t *testing.T) {
+	for range 100 {
+		p := NewPool(2, 64, nil)
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 50 {
+					p.Execute(func() {})
+				}
+			}()
+		}
+		p.Close()
+		wg.Wait()
+		assert.Equal(t, int64(0), p.Queued())
+		assert.Equal(t, int64(0), p.Running())
+	}
+}
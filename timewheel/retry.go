@@ -0,0 +1,232 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Backoff delay before the next attempt given the count of failed attempts so far and the previous delay
+type Backoff interface {
+	Next(attempt int, prev time.Duration) time.Duration
+}
+
+type constantBackoff struct {
+	d time.Duration
+}
+
+func (b constantBackoff) Next(int, time.Duration) time.Duration {
+	return b.d
+}
+
+// Constant wait d between attempts
+func Constant(d time.Duration) Backoff {
+	return constantBackoff{d: d}
+}
+
+type exponentialBackoff struct {
+	base   time.Duration
+	max    time.Duration
+	factor float64
+}
+
+func (b exponentialBackoff) Next(attempt int, _ time.Duration) time.Duration {
+	d := float64(b.base) * math.Pow(b.factor, float64(attempt-1))
+	if b.max > 0 && d >= float64(b.max) {
+		return b.max
+	}
+	if d >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// Exponential wait base*factor^(attempt-1) capped at max, max <= 0 means no cap and factor below 1
+// falls back to 2
+func Exponential(base, max time.Duration, factor float64) Backoff {
+	if base <= 0 {
+		panic("base must be greater than zero")
+	}
+	if factor < 1 {
+		factor = 2
+	}
+	return exponentialBackoff{base: base, max: max, factor: factor}
+}
+
+type decorrelatedJitter struct {
+	base time.Duration
+	max  time.Duration
+}
+
+func (b decorrelatedJitter) Next(_ int, prev time.Duration) time.Duration {
+	prev = min(max(prev, b.base), math.MaxInt64/3)
+	d := b.base + rand.N(prev*3-b.base+1)
+	if b.max > 0 {
+		return min(d, b.max)
+	}
+	return d
+}
+
+// DecorrelatedJitter wait a random delay in [base, 3*previous delay] capped at max, max <= 0 means no cap
+func DecorrelatedJitter(base, max time.Duration) Backoff {
+	if base <= 0 {
+		panic("base must be greater than zero")
+	}
+	return decorrelatedJitter{base: base, max: max}
+}
+
+// RetryPolicy how Retry spaces and bounds the attempts
+type RetryPolicy struct {
+	Backoff Backoff
+	// MaxAttempts count of attempts including the first one, 0 for no limit
+	MaxAttempts int
+	// Deadline overall budget measured from the first attempt, 0 for no limit
+	Deadline time.Duration
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wrap err to make Retry give up at once and report err
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Retrying a retry loop started by Retry, a waiting retry is a single timer in the wheel
+type Retrying struct {
+	tw       *TimeWheel
+	ctx      context.Context
+	op       func(ctx context.Context) error
+	policy   RetryPolicy
+	start    time.Time
+	prev     time.Duration
+	attempts atomic.Int32
+	mu       sync.Mutex
+	timer    *Timer
+	stopCtx  func() bool
+	finished bool
+	err      error
+	done     chan struct{}
+}
+
+// Retry call op until it succeeds, returns a Permanent error, the policy gives up or ctx is done.
+// The first attempt runs on the caller. The following ones run on the wheel's Executor when one is
+// configured, otherwise on a goroutine started when the backoff elapsed, so a slow op never holds up
+// the other timers and a waiting retry costs only its wheel entry.
+func (tw *TimeWheel) Retry(ctx context.Context, op func(ctx context.Context) error, policy RetryPolicy) *Retrying {
+	if policy.Backoff == nil {
+		panic("retry policy needs a backoff")
+	}
+	r := &Retrying{tw: tw, ctx: ctx, op: op, policy: policy, start: tw.clock.Now(), done: make(chan struct{})}
+	r.mu.Lock()
+	r.stopCtx = context.AfterFunc(ctx, func() {
+		r.finish(context.Cause(ctx))
+	})
+	r.mu.Unlock()
+	r.attempt()
+	return r
+}
+
+// Done closed once the retry loop ended
+func (r *Retrying) Done() <-chan struct{} {
+	return r.done
+}
+
+// Err nil if op eventually succeeded, otherwise its last error or the cause of ctx, valid once Done is closed
+func (r *Retrying) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Wait block until the loop ended and return Err
+func (r *Retrying) Wait() error {
+	<-r.done
+	return r.Err()
+}
+
+// Attempts count of op calls so far
+func (r *Retrying) Attempts() int {
+	return int(r.attempts.Load())
+}
+
+func (r *Retrying) attempt() {
+	if r.ctx.Err() != nil {
+		r.finish(context.Cause(r.ctx))
+		return
+	}
+	n := int(r.attempts.Add(1))
+	err := r.op(r.ctx)
+	if err == nil {
+		r.finish(nil)
+		return
+	}
+	var perm *permanentError
+	if errors.As(err, &perm) {
+		r.finish(perm.err)
+		return
+	}
+	if r.policy.MaxAttempts > 0 && n >= r.policy.MaxAttempts {
+		r.finish(err)
+		return
+	}
+	r.prev = r.policy.Backoff.Next(n, r.prev)
+	if r.policy.Deadline > 0 && r.tw.clock.Now().Add(r.prev).After(r.start.Add(r.policy.Deadline)) {
+		r.finish(err)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.finished {
+		return
+	}
+	if r.timer == nil {
+		r.timer = r.tw.AfterFunc(r.prev, r.fire)
+	} else {
+		r.timer.Reset(r.prev)
+	}
+}
+
+// fire run the next attempt off the tick goroutine
+func (r *Retrying) fire() {
+	if r.tw.executor != nil {
+		r.attempt()
+		return
+	}
+	go r.attempt()
+}
+
+func (r *Retrying) finish(err error) {
+	r.mu.Lock()
+	if r.finished {
+		r.mu.Unlock()
+		return
+	}
+	r.finished = true
+	r.err = err
+	timer, stopCtx := r.timer, r.stopCtx
+	close(r.done)
+	r.mu.Unlock()
+	if timer != nil {
+		timer.Stop()
+	}
+	if stopCtx != nil {
+		stopCtx()
+	}
+}
@@ -0,0 +1,137 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+var errFlaky = errors.New("flaky")
+
+// inlineExecutor run the attempts on the fake clock goroutine so the tests advance in lockstep with them
+type inlineExecutor struct{}
+
+func (inlineExecutor) Execute(fn func()) {
+	fn()
+}
+
+func TestRetrySucceed(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 16, WithClock(clock), WithExecutor(inlineExecutor{}))
+	defer tw.Close()
+	var at []time.Duration
+	r := tw.Retry(context.Background(), func(ctx context.Context) error {
+		at = append(at, clock.Now().Sub(time.Unix(0, 0)))
+		if len(at) < 4 {
+			return errFlaky
+		}
+		return nil
+	}, RetryPolicy{Backoff: Exponential(10*time.Millisecond, 30*time.Millisecond, 2)})
+	assert.Equal(t, 1, r.Attempts())
+	clock.Advance(time.Second)
+	assert.NoError(t, r.Wait())
+	assert.Equal(t, []time.Duration{0, 10 * time.Millisecond, 30 * time.Millisecond, 60 * time.Millisecond}, at)
+}
+
+func TestRetryGiveUp(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 16, WithClock(clock), WithExecutor(inlineExecutor{}))
+	defer tw.Close()
+	op := func(ctx context.Context) error {
+		return errFlaky
+	}
+	byAttempts := tw.Retry(context.Background(), op, RetryPolicy{Backoff: Constant(time.Millisecond), MaxAttempts: 5})
+	byDeadline := tw.Retry(context.Background(), op, RetryPolicy{Backoff: Constant(10 * time.Millisecond), Deadline: 35 * time.Millisecond})
+	clock.Advance(time.Second)
+	assert.ErrorIs(t, byAttempts.Wait(), errFlaky)
+	assert.Equal(t, 5, byAttempts.Attempts())
+	assert.ErrorIs(t, byDeadline.Wait(), errFlaky)
+	assert.Equal(t, 4, byDeadline.Attempts())
+
+	errFatal := errors.New("fatal")
+	r := tw.Retry(context.Background(), func(ctx context.Context) error {
+		return Permanent(errFatal)
+	}, RetryPolicy{Backoff: Constant(time.Millisecond)})
+	assert.Equal(t, errFatal, r.Wait())
+	assert.Equal(t, 1, r.Attempts())
+}
+
+func TestRetryCancel(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 16, WithClock(clock), WithExecutor(inlineExecutor{}))
+	defer tw.Close()
+	errStop := errors.New("stop")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	r := tw.Retry(ctx, func(ctx context.Context) error {
+		return errFlaky
+	}, RetryPolicy{Backoff: DecorrelatedJitter(time.Millisecond, time.Second)})
+	clock.Advance(100 * time.Millisecond)
+	attempts := r.Attempts()
+	assert.Greater(t, attempts, 1)
+	cancel(errStop)
+	assert.ErrorIs(t, r.Wait(), errStop)
+	clock.Advance(time.Minute)
+	assert.Equal(t, attempts, r.Attempts())
+	assert.Equal(t, 0, tw.pending())
+}
+
+func TestDecorrelatedJitter(t *testing.T) {
+	b := DecorrelatedJitter(10*time.Millisecond, 100*time.Millisecond)
+	var prev time.Duration
+	for i := 1; i < 100; i++ {
+		d := b.Next(i, prev)
+		assert.GreaterOrEqual(t, d, 10*time.Millisecond)
+		assert.LessOrEqual(t, d, min(100*time.Millisecond, max(prev, 10*time.Millisecond)*3))
+		prev = d
+	}
+}
+
+func TestBackoffUncapped(t *testing.T) {
+	b := Exponential(10*time.Millisecond, 0, 2)
+	assert.Equal(t, 10*time.Millisecond, b.Next(1, 0))
+	assert.Equal(t, 80*time.Millisecond, b.Next(4, 0))
+	assert.Equal(t, time.Duration(math.MaxInt64), b.Next(1000, 0))
+	assert.Panics(t, func() {
+		Exponential(0, time.Second, 2)
+	})
+
+	j := DecorrelatedJitter(time.Millisecond, 0)
+	prev := time.Duration(0)
+	for i := 1; i < 200; i++ {
+		prev = j.Next(i, prev)
+		assert.GreaterOrEqual(t, prev, time.Millisecond)
+	}
+}
+
+func TestRetryBlockingOp(t *testing.T) {
+	tw := New(time.Millisecond, 16)
+	defer tw.Close()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := tw.Retry(context.Background(), func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+			// the second attempt blocks like a hung RPC
+			<-release
+			return nil
+		default:
+			return errFlaky
+		}
+	}, RetryPolicy{Backoff: Constant(5 * time.Millisecond)})
+	<-started
+	fired := make(chan struct{})
+	tw.AfterFunc(10*time.Millisecond, func() {
+		close(fired)
+	})
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("timer held up by a blocked attempt")
+	}
+	close(release)
+	assert.NoError(t, r.Wait())
+	assert.GreaterOrEqual(t, r.Attempts(), 2)
+}
@@ -0,0 +1,159 @@
+package timewheel
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// MissedPolicy how a periodic job catches up with the runs that fell due while the wheel lagged
+type MissedPolicy uint8
+
+const (
+	// FireOnce run once for the due run and all the missed ones
+	FireOnce MissedPolicy = iota
+	// FireAll run once per missed run
+	FireAll
+	// Skip drop a late run with everything it missed and wait for the next planned one, a run only
+	// counts as late once the next one is due too, so Every rejects intervals below the wheel tick
+	Skip
+)
+
+// Job periodic job scheduled on a TimeWheel, runs are planned from the previous planned time
+// rather than the actual fire time so the schedule does not drift under load
+type Job struct {
+	tw      *TimeWheel
+	fn      func()
+	next    func(time.Time) time.Time
+	policy  MissedPolicy
+	jitter  time.Duration
+	mu      sync.Mutex
+	planned time.Time
+	offset  time.Duration
+	timer   *Timer
+	stopped bool
+}
+
+// JobOption customize a periodic Job
+type JobOption func(j *Job)
+
+// WithMissedPolicy select how missed runs are handled, FireOnce by default
+func WithMissedPolicy(p MissedPolicy) JobOption {
+	return func(j *Job) {
+		j.policy = p
+	}
+}
+
+// WithJitter delay every run by a random duration in [0,jitter), the plan itself is not shifted
+func WithJitter(jitter time.Duration) JobOption {
+	return func(j *Job) {
+		j.jitter = jitter
+	}
+}
+
+// Every run fn at a fixed rate of interval, with Skip the interval must not be below the tick since
+// every run would be late and none would ever fire
+func (tw *TimeWheel) Every(interval time.Duration, fn func(), opts ...JobOption) *Job {
+	if interval <= 0 {
+		panic("interval must be greater than zero")
+	}
+	return tw.startJob(fn, func(t time.Time) time.Time {
+		return t.Add(interval)
+	}, opts, func(j *Job) {
+		if j.policy == Skip && interval < tw.tick {
+			panic("interval must not be below the tick with Skip")
+		}
+	})
+}
+
+// Cron run fn at the minutes matching the five field cron expression, descriptors like @hourly are accepted
+func (tw *TimeWheel) Cron(expr string, fn func(), opts ...JobOption) (*Job, error) {
+	sched, err := parseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+	return tw.startJob(fn, sched.next, opts, nil), nil
+}
+
+func (tw *TimeWheel) startJob(fn func(), next func(time.Time) time.Time, opts []JobOption, check func(j *Job)) *Job {
+	j := &Job{tw: tw, fn: fn, next: next}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if check != nil {
+		check(j)
+	}
+	now := tw.clock.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.planned = next(now)
+	if j.planned.IsZero() {
+		j.stopped = true
+		return j
+	}
+	j.timer = tw.AfterFunc(j.delay(now), j.fire)
+	return j
+}
+
+// Stop cancel all future runs, a run already in progress is not interrupted
+func (j *Job) Stop() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.stopped = true
+	if j.timer != nil {
+		j.timer.Stop()
+	}
+}
+
+// Next planned time of the next run, zero if the job stopped
+func (j *Job) Next() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.stopped {
+		return time.Time{}
+	}
+	return j.planned
+}
+
+// delay pick the jitter of the planned run and return how long to wait for it
+func (j *Job) delay(now time.Time) time.Duration {
+	j.offset = 0
+	if j.jitter > 0 {
+		j.offset = rand.N(j.jitter)
+	}
+	return j.planned.Add(j.offset).Sub(now)
+}
+
+func (j *Job) fire() {
+	now := j.tw.clock.Now()
+	j.mu.Lock()
+	if j.stopped {
+		j.mu.Unlock()
+		return
+	}
+	runs := 1
+	deadline := now.Add(-j.offset)
+	next := j.next(j.planned)
+	for !next.IsZero() && !next.After(deadline) {
+		runs++
+		next = j.next(next)
+	}
+	switch j.policy {
+	case FireOnce:
+		runs = 1
+	case Skip:
+		if runs > 1 {
+			runs = 0
+		}
+	}
+	j.planned = next
+	if next.IsZero() {
+		j.stopped = true
+	} else {
+		j.timer.Reset(j.delay(now))
+	}
+	j.mu.Unlock()
+	for range runs {
+		j.fn()
+	}
+}
@@ -0,0 +1,84 @@
+package timewheel
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEvery(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 8, WithClock(clock))
+	defer tw.Close()
+	var at []time.Duration
+	job := tw.Every(10*time.Millisecond, func() {
+		at = append(at, clock.Now().Sub(time.Unix(0, 0)))
+	})
+	clock.Advance(35 * time.Millisecond)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}, at)
+	assert.Equal(t, time.Unix(0, 0).Add(40*time.Millisecond), job.Next())
+	job.Stop()
+	clock.Advance(time.Second)
+	assert.Equal(t, 3, len(at))
+	assert.True(t, job.Next().IsZero())
+}
+
+func TestMissedPolicy(t *testing.T) {
+	for _, c := range []struct {
+		policy   MissedPolicy
+		interval time.Duration
+		runs     int
+	}{{FireOnce, 3 * time.Millisecond, 4}, {FireAll, 3 * time.Millisecond, 13}, {Skip, 10 * time.Millisecond, 4}} {
+		clock := NewFakeClock(time.Unix(0, 0))
+		// a coarse wheel makes every run below the tick late by several intervals
+		tw := New(10*time.Millisecond, 8, WithClock(clock))
+		var runs int
+		tw.Every(c.interval, func() {
+			runs++
+		}, WithMissedPolicy(c.policy))
+		clock.Advance(40 * time.Millisecond)
+		tw.Close()
+		assert.Equal(t, c.runs, runs, "policy %d", c.policy)
+	}
+	tw := New(10*time.Millisecond, 8, WithClock(NewFakeClock(time.Unix(0, 0))))
+	defer tw.Close()
+	assert.Panics(t, func() {
+		tw.Every(3*time.Millisecond, func() {}, WithMissedPolicy(Skip))
+	})
+}
+
+func TestJitter(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Millisecond, 8, WithClock(clock))
+	defer tw.Close()
+	var at []time.Time
+	tw.Every(100*time.Millisecond, func() {
+		at = append(at, clock.Now())
+	}, WithJitter(20*time.Millisecond))
+	clock.Advance(time.Second + 20*time.Millisecond)
+	assert.Equal(t, 10, len(at))
+	for i, v := range at {
+		planned := time.Unix(0, 0).Add(time.Duration(i+1) * 100 * time.Millisecond)
+		assert.False(t, v.Before(planned))
+		assert.Less(t, v.Sub(planned), 21*time.Millisecond)
+	}
+}
+
+func TestCronJob(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC)
+	clock := NewFakeClock(start)
+	tw := New(time.Second, 60, WithClock(clock))
+	defer tw.Close()
+	var at []time.Time
+	_, err := tw.Cron("*/5 * * * *", func() {
+		at = append(at, clock.Now())
+	})
+	assert.NoError(t, err)
+	clock.Advance(11 * time.Minute)
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC),
+	}, at)
+	_, err = tw.Cron("* * *", func() {})
+	assert.Error(t, err)
+}
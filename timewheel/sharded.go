@@ -0,0 +1,51 @@
+package timewheel
+
+import (
+	"gotools/internal/mhash"
+	"time"
+)
+
+// Sharded spread timers over independent wheels picked by the current M, so concurrent
+// AfterFunc/Stop calls rarely contend on the same lock or tick goroutine
+type Sharded struct {
+	shards []*TimeWheel
+	mask   uint64
+}
+
+// NewSharded create shards wheels rounded up to a power of two, each built by New(tick, slots, opts...)
+func NewSharded(shards int, tick time.Duration, slots int, opts ...Option) *Sharded {
+	if shards <= 0 {
+		panic("shards must be greater than zero")
+	}
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+	s := &Sharded{shards: make([]*TimeWheel, n), mask: uint64(n - 1)}
+	for i := range s.shards {
+		s.shards[i] = New(tick, slots, opts...)
+	}
+	return s
+}
+
+// AfterFunc call fn once d elapsed on one of the shards
+func (s *Sharded) AfterFunc(d time.Duration, fn func()) *Timer {
+	return s.Shard().AfterFunc(d, fn)
+}
+
+// Shard wheel assigned to the caller
+func (s *Sharded) Shard() *TimeWheel {
+	return s.shards[mhash.Hash()&s.mask]
+}
+
+// Shards all the underlying wheels
+func (s *Sharded) Shards() []*TimeWheel {
+	return s.shards
+}
+
+// Close stop all the shards
+func (s *Sharded) Close() {
+	for _, tw := range s.shards {
+		tw.Close()
+	}
+}
@@ -0,0 +1,39 @@
+package timewheel
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSharded(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	s := NewSharded(3, time.Millisecond, 16, WithClock(clock))
+	defer s.Close()
+	assert.Equal(t, 4, len(s.Shards()))
+	var fired atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				tm := s.AfterFunc(time.Duration(i%50+1)*time.Millisecond, func() {
+					fired.Add(1)
+				})
+				if i%2 == 1 {
+					assert.True(t, tm.Stop())
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	clock.Advance(50 * time.Millisecond)
+	assert.Equal(t, int32(4000), fired.Load())
+	st := s.Stats()
+	assert.Equal(t, int64(4000), st.Fired)
+	assert.Equal(t, int64(4000), st.Cancelled)
+	assert.Equal(t, int64(0), st.Pending)
+}
@@ -0,0 +1,173 @@
+package timewheel
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"slices"
+	"time"
+)
+
+const (
+	snapshotMagic   uint32 = 0x54574e53 // "SNWT"
+	snapshotVersion uint32 = 2
+	// maxPayloadSize largest encoded payload Restore accepts, guards allocations against corrupted sizes
+	maxPayloadSize = 16 << 20
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+var ErrInvalidSnapshot = errors.New("invalid timewheel snapshot")
+
+// OverduePolicy what Restore does with timers whose deadline passed while the process was down
+type OverduePolicy uint8
+
+const (
+	// FireOverdue fire overdue timers on the next tick
+	FireOverdue OverduePolicy = iota
+	// DropOverdue discard overdue timers
+	DropOverdue
+	// ShiftOverdue restart overdue timers with the delay they had left when the snapshot was taken
+	ShiftOverdue
+)
+
+type snapshotRecord struct {
+	remaining int64
+	payload   any
+}
+
+// Snapshot write every pending timer created by AfterPayload to w as its remaining delay followed by
+// its payload encoded by encode, the snapshot also records the clock time it was taken at and ends with
+// the CRC-32C of everything before it. Payloads may not exceed 16MiB once encoded.
+func (tw *TimeWheel) Snapshot(w io.Writer, encode func(payload any) ([]byte, error)) error {
+	tw.mu.Lock()
+	now := tw.clock.Now()
+	elapsed := now.Sub(tw.start).Nanoseconds()
+	records := make([]snapshotRecord, 0)
+	for _, l := range tw.levels {
+		for i := range l.buckets {
+			b := &l.buckets[i]
+			for t := b.root.next; t != &b.root; t = t.next {
+				if t.data != nil {
+					records = append(records, snapshotRecord{remaining: t.when - elapsed, payload: t.data})
+				}
+			}
+		}
+	}
+	tw.mu.Unlock()
+	slices.SortStableFunc(records, func(a, b snapshotRecord) int {
+		return cmp.Compare(a.remaining, b.remaining)
+	})
+
+	buf := bufio.NewWriter(w)
+	crc := crc32.New(castagnoli)
+	bw := io.MultiWriter(buf, crc)
+	header := []any{snapshotMagic, snapshotVersion, now.UnixNano(), uint64(len(records))}
+	for _, v := range header {
+		if err := binary.Write(bw, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+	for _, r := range records {
+		data, err := encode(r.payload)
+		if err != nil {
+			return err
+		}
+		if len(data) > maxPayloadSize {
+			return fmt.Errorf("payload of %d bytes exceeds %d", len(data), maxPayloadSize)
+		}
+		if err = binary.Write(bw, binary.LittleEndian, r.remaining); err != nil {
+			return err
+		}
+		if err = binary.Write(bw, binary.LittleEndian, uint32(len(data))); err != nil {
+			return err
+		}
+		if _, err = bw.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := binary.Write(buf, binary.LittleEndian, crc.Sum32()); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// Restore schedule on tw the timers saved by Snapshot, the time elapsed since the snapshot is taken off
+// their delay. Every payload is rebuilt by decode and passed to fn when its timer fires. Nothing is
+// scheduled unless the whole snapshot reads back intact. Return the count of timers scheduled.
+func (tw *TimeWheel) Restore(r io.Reader, policy OverduePolicy, decode func(data []byte) (any, error), fn func(payload any)) (int, error) {
+	crc := crc32.New(castagnoli)
+	br := bufio.NewReader(r)
+	tr := io.TeeReader(br, crc)
+	var magic, version uint32
+	var takenAt int64
+	var count uint64
+	for _, v := range []any{&magic, &version, &takenAt, &count} {
+		if err := binary.Read(tr, binary.LittleEndian, v); err != nil {
+			return 0, err
+		}
+	}
+	if magic != snapshotMagic || version != snapshotVersion {
+		return 0, ErrInvalidSnapshot
+	}
+	type rawRecord struct {
+		remaining int64
+		data      []byte
+	}
+	// count is not trusted for allocation, records only grow as they are actually read
+	var raws []rawRecord
+	for range count {
+		var remaining int64
+		var size uint32
+		if err := binary.Read(tr, binary.LittleEndian, &remaining); err != nil {
+			return 0, err
+		}
+		if err := binary.Read(tr, binary.LittleEndian, &size); err != nil {
+			return 0, err
+		}
+		if size > maxPayloadSize {
+			return 0, ErrInvalidSnapshot
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(tr, data); err != nil {
+			return 0, err
+		}
+		raws = append(raws, rawRecord{remaining, data})
+	}
+	sum := crc.Sum32()
+	var trailer uint32
+	if err := binary.Read(br, binary.LittleEndian, &trailer); err != nil {
+		return 0, err
+	}
+	if trailer != sum {
+		return 0, ErrInvalidSnapshot
+	}
+	payloads := make([]any, len(raws))
+	for i, raw := range raws {
+		payload, err := decode(raw.data)
+		if err != nil {
+			return 0, err
+		}
+		payloads[i] = payload
+	}
+	downtime := tw.clock.Now().Sub(time.Unix(0, takenAt))
+	var restored int
+	for i, raw := range raws {
+		d := time.Duration(raw.remaining) - downtime
+		if d <= 0 {
+			switch policy {
+			case DropOverdue:
+				continue
+			case ShiftOverdue:
+				d = time.Duration(raw.remaining)
+			}
+		}
+		tw.AfterPayload(d, payloads[i], fn)
+		restored++
+	}
+	return restored, nil
+}
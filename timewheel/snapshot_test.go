@@ -0,0 +1,94 @@
+package timewheel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func encodeInt(payload any) ([]byte, error) {
+	return []byte(strconv.Itoa(payload.(int))), nil
+}
+
+func decodeInt(data []byte) (any, error) {
+	return strconv.Atoi(string(data))
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	for _, c := range []struct {
+		policy OverduePolicy
+		fired  []int
+	}{
+		{FireOverdue, []int{10, 20, 30, 60}},
+		{DropOverdue, []int{30, 60}},
+		{ShiftOverdue, []int{10, 30, 20, 60}},
+	} {
+		clock := NewFakeClock(time.Unix(100, 0))
+		tw := New(time.Second, 8, WithClock(clock))
+		for _, v := range []int{60, 10, 30, 20} {
+			tw.AfterPayload(time.Duration(v)*time.Second, v, func(any) {})
+		}
+		tw.AfterFunc(time.Second, func() {})
+		clock.Advance(5 * time.Second)
+		buf := new(bytes.Buffer)
+		assert.NoError(t, tw.Snapshot(buf, encodeInt))
+		tw.Close()
+
+		// restart 20s later, timers of 10s and 20s are overdue
+		clock.Advance(20 * time.Second)
+		tw = New(time.Second, 8, WithClock(clock))
+		var fired []int
+		n, err := tw.Restore(buf, c.policy, decodeInt, func(payload any) {
+			fired = append(fired, payload.(int))
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, len(c.fired), n)
+		clock.Advance(time.Second)
+		clock.Advance(10 * time.Second)
+		clock.Advance(time.Minute)
+		assert.Equal(t, c.fired, fired, "policy %d", c.policy)
+		tw.Close()
+	}
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	tw := New(time.Second, 8, WithClock(NewFakeClock(time.Unix(0, 0))))
+	defer tw.Close()
+	_, err := tw.Restore(bytes.NewReader(make([]byte, 24)), FireOverdue, decodeInt, func(any) {})
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+	_, err = tw.Restore(bytes.NewReader(nil), FireOverdue, decodeInt, func(any) {})
+	assert.Error(t, err)
+}
+
+func TestRestoreCorrupted(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	tw := New(time.Second, 8, WithClock(clock))
+	defer tw.Close()
+	tw.AfterPayload(time.Minute, 7, func(any) {})
+	tw.AfterPayload(time.Hour, 8, func(any) {})
+	buf := new(bytes.Buffer)
+	assert.Nil(t, tw.Snapshot(buf, encodeInt))
+	data := buf.Bytes()
+
+	restored := New(time.Second, 8, WithClock(clock))
+	defer restored.Close()
+	flipped := bytes.Clone(data)
+	flipped[len(flipped)-6] ^= 1
+	n, err := restored.Restore(bytes.NewReader(flipped), FireOverdue, decodeInt, func(any) {})
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 0, restored.pending())
+
+	// a huge payload size is rejected before allocating it
+	huge := bytes.Clone(data)
+	binary.LittleEndian.PutUint32(huge[24+8:], 1<<31)
+	_, err = restored.Restore(bytes.NewReader(huge), FireOverdue, decodeInt, func(any) {})
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+
+	n, err = restored.Restore(bytes.NewReader(data), FireOverdue, decodeInt, func(any) {})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+}
@@ -1 +1,291 @@
 package timewheel
+
+import (
+	"cmp"
+	"gotools/i64adder"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Timer a single timeout scheduled on a TimeWheel
+type Timer struct {
+	tw     *TimeWheel
+	when   int64 // deadline, nanoseconds since wheel start
+	expire int64 // tick the timer fires at
+	fn     func()
+	data   any
+	prev   *Timer
+	next   *Timer
+	b      *bucket
+}
+
+// Stop prevents the timer from firing, return false if it already fired or been stopped
+func (t *Timer) Stop() bool {
+	tw := t.tw
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if t.b == nil {
+		return false
+	}
+	t.b.remove(t)
+	tw.pendingCnt.Decr()
+	tw.cancelledCnt.Incr()
+	return true
+}
+
+// Reset reschedules the timer to fire after d, return true if the timer was pending
+func (t *Timer) Reset(d time.Duration) bool {
+	tw := t.tw
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	active := t.b != nil
+	if active {
+		t.b.remove(t)
+	} else {
+		tw.pendingCnt.Incr()
+	}
+	tw.schedule(t, d)
+	return active
+}
+
+// Payload payload given to AfterPayload, nil for other timers
+func (t *Timer) Payload() any {
+	return t.data
+}
+
+type bucket struct {
+	root Timer
+}
+
+func (b *bucket) init() {
+	b.root.next = &b.root
+	b.root.prev = &b.root
+}
+
+func (b *bucket) push(t *Timer) {
+	t.prev = b.root.prev
+	t.next = &b.root
+	b.root.prev.next = t
+	b.root.prev = t
+	t.b = b
+}
+
+func (b *bucket) remove(t *Timer) {
+	t.prev.next = t.next
+	t.next.prev = t.prev
+	t.prev, t.next, t.b = nil, nil, nil
+}
+
+// drain detach all timers of the bucket and append them to dst
+func (b *bucket) drain(dst []*Timer) []*Timer {
+	for t := b.root.next; t != &b.root; {
+		next := t.next
+		t.prev, t.next, t.b = nil, nil, nil
+		dst = append(dst, t)
+		t = next
+	}
+	b.init()
+	return dst
+}
+
+type level struct {
+	span    int64 // ticks covered by one slot
+	buckets []bucket
+}
+
+func newLevel(span int64, slots int64) *level {
+	lv := &level{span: span, buckets: make([]bucket, slots)}
+	for i := range lv.buckets {
+		lv.buckets[i].init()
+	}
+	return lv
+}
+
+// TimeWheel hierarchical timing wheel, timers beyond the range of a level overflow into the next
+// level which is created on demand. Callbacks run on the clock tick goroutine and must not block
+// unless an Executor is configured.
+type TimeWheel struct {
+	mu       sync.Mutex
+	tick     time.Duration
+	slots    int64
+	clock    Clock
+	start    time.Time
+	cur      int64
+	levels   []*level
+	expired  []*Timer
+	executor Executor
+	stop     func()
+	once     sync.Once
+
+	pendingCnt   *i64adder.Adder
+	firedCnt     *i64adder.Adder
+	cancelledCnt *i64adder.Adder
+	tickLag      atomic.Int64
+	maxTickLag   atomic.Int64
+	latency      latencyHistogram
+}
+
+// Option customize a TimeWheel on creation
+type Option func(tw *TimeWheel)
+
+// WithClock drive the wheel by the given clock instead of RealClock
+func WithClock(clock Clock) Option {
+	return func(tw *TimeWheel) {
+		tw.clock = clock
+	}
+}
+
+// New create a wheel advancing every tick with slots buckets per level
+func New(tick time.Duration, slots int, opts ...Option) *TimeWheel {
+	if tick <= 0 {
+		panic("tick must be greater than zero")
+	}
+	if slots < 2 {
+		panic("slots must be at least 2")
+	}
+	tw := &TimeWheel{tick: tick, slots: int64(slots), clock: RealClock,
+		pendingCnt: i64adder.New(), firedCnt: i64adder.New(), cancelledCnt: i64adder.New()}
+	for _, opt := range opts {
+		opt(tw)
+	}
+	tw.start = tw.clock.Now()
+	tw.levels = append(tw.levels, newLevel(1, tw.slots))
+	tw.stop = tw.clock.Tick(tick, func() {
+		tw.advance(tw.elapsed())
+	})
+	return tw
+}
+
+// Tick duration of one tick
+func (tw *TimeWheel) Tick() time.Duration {
+	return tw.tick
+}
+
+// AfterFunc call fn on the wheel goroutine once d elapsed
+func (tw *TimeWheel) AfterFunc(d time.Duration, fn func()) *Timer {
+	t := &Timer{tw: tw, fn: fn}
+	tw.mu.Lock()
+	tw.schedule(t, d)
+	tw.pendingCnt.Incr()
+	tw.mu.Unlock()
+	return t
+}
+
+// AfterPayload call fn with payload once d elapsed, only timers carrying a payload are saved by Snapshot
+func (tw *TimeWheel) AfterPayload(d time.Duration, payload any, fn func(payload any)) *Timer {
+	t := &Timer{tw: tw, data: payload}
+	t.fn = func() {
+		fn(payload)
+	}
+	tw.mu.Lock()
+	tw.schedule(t, d)
+	tw.pendingCnt.Incr()
+	tw.mu.Unlock()
+	return t
+}
+
+// Clock clock driving the wheel
+func (tw *TimeWheel) Clock() Clock {
+	return tw.clock
+}
+
+// Close stop the wheel, pending timers will never fire
+func (tw *TimeWheel) Close() {
+	tw.once.Do(tw.stop)
+}
+
+// elapsed nanoseconds since the wheel start
+func (tw *TimeWheel) elapsed() int64 {
+	return tw.clock.Now().Sub(tw.start).Nanoseconds()
+}
+
+func (tw *TimeWheel) schedule(t *Timer, d time.Duration) {
+	now := tw.elapsed()
+	t.when = now + d.Nanoseconds()
+	if d > 0 && t.when < now {
+		// saturate instead of wrapping into the past for huge durations
+		t.when = math.MaxInt64
+	}
+	tick := tw.tick.Nanoseconds()
+	t.expire = t.when / tick
+	if t.when%tick != 0 {
+		t.expire++
+	}
+	if t.expire <= tw.cur {
+		t.expire = tw.cur + 1
+	}
+	tw.place(t)
+}
+
+func (tw *TimeWheel) place(t *Timer) {
+	delta := t.expire - tw.cur
+	if delta < 0 {
+		delta = 0
+	}
+	var lv = 0
+	for delta/tw.levels[lv].span >= tw.slots {
+		if tw.levels[lv].span > math.MaxInt64/tw.slots {
+			// the top level cannot widen further, the timer is placed again when its bucket cascades
+			break
+		}
+		lv++
+		if lv == len(tw.levels) {
+			tw.levels = append(tw.levels, newLevel(tw.levels[lv-1].span*tw.slots, tw.slots))
+		}
+	}
+	l := tw.levels[lv]
+	l.buckets[(t.expire/l.span)%tw.slots].push(t)
+}
+
+// advance move the wheel forward until now and fire all due timers in deadline order
+func (tw *TimeWheel) advance(now int64) {
+	target := now / tw.tick.Nanoseconds()
+	tw.mu.Lock()
+	tw.observeLag(now)
+	for tw.cur < target {
+		tw.cur++
+		tw.cascade()
+		tw.expired = tw.levels[0].buckets[tw.cur%tw.slots].drain(tw.expired[:0])
+		if len(tw.expired) == 0 {
+			continue
+		}
+		slices.SortStableFunc(tw.expired, func(a, b *Timer) int {
+			return cmp.Compare(a.when, b.when)
+		})
+		tw.mu.Unlock()
+		tw.pendingCnt.Add(-int64(len(tw.expired)))
+		tw.firedCnt.Add(int64(len(tw.expired)))
+		fireAt := tw.elapsed()
+		for i, t := range tw.expired {
+			tw.latency.observe(time.Duration(fireAt - t.when))
+			if tw.executor != nil {
+				tw.executor.Execute(t.fn)
+			} else {
+				t.fn()
+			}
+			tw.expired[i] = nil
+		}
+		tw.mu.Lock()
+	}
+	tw.mu.Unlock()
+}
+
+// cascade redistribute the timers of the higher level buckets reached by current tick
+func (tw *TimeWheel) cascade() {
+	for lv := 1; lv < len(tw.levels); lv++ {
+		l := tw.levels[lv]
+		if tw.cur%l.span != 0 {
+			return
+		}
+		b := &l.buckets[(tw.cur/l.span)%tw.slots]
+		for t := b.root.next; t != &b.root; {
+			next := t.next
+			b.remove(t)
+			tw.place(t)
+			t = next
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package timewheel
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func BenchmarkTimeAfterFunc(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			time.AfterFunc(time.Second, func() {}).Stop()
+		}
+	})
+}
+
+func BenchmarkTimeWheel(b *testing.B) {
+	tw := New(time.Millisecond, 512)
+	defer tw.Close()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			tw.AfterFunc(time.Second, func() {}).Stop()
+		}
+	})
+}
+
+func BenchmarkShardedTimeWheel(b *testing.B) {
+	s := NewSharded(16, time.Millisecond, 512)
+	defer s.Close()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.AfterFunc(time.Second, func() {}).Stop()
+		}
+	})
+}
+
+func BenchmarkShardedTimeWheelPending(b *testing.B) {
+	s := NewSharded(16, time.Millisecond, 512)
+	defer s.Close()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.AfterFunc(time.Minute, func() {})
+		}
+	})
+}
+
+func BenchmarkTimeAfterFuncPending(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			time.AfterFunc(time.Minute, func() {})
+		}
+	})
+}
+
+func BenchmarkContextWithTimeout(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, cancel := context.WithTimeout(context.Background(), time.Second)
+			cancel()
+		}
+	})
+}
+
+func BenchmarkTimeWheelWithTimeout(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, cancel := WithTimeout(context.Background(), time.Second)
+			cancel()
+		}
+	})
+}
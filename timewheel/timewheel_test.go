@@ -1 +1,88 @@
 package timewheel
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAfterFunc(t *testing.T) {
+	tw := New(time.Millisecond, 8)
+	defer tw.Close()
+	begin := time.Now()
+	done := make(chan time.Duration, 1)
+	tw.AfterFunc(30*time.Millisecond, func() {
+		done <- time.Since(begin)
+	})
+	select {
+	case cost := <-done:
+		assert.GreaterOrEqual(t, cost, 30*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("timer not fired")
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	tw := New(time.Millisecond, 4)
+	defer tw.Close()
+	var wg sync.WaitGroup
+	var fired atomic.Int32
+	for i := 1; i <= 100; i++ {
+		wg.Add(1)
+		tw.AfterFunc(time.Duration(i)*time.Millisecond, func() {
+			fired.Add(1)
+			wg.Done()
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, int32(100), fired.Load())
+	assert.Greater(t, len(tw.levels), 2)
+}
+
+func TestStop(t *testing.T) {
+	tw := New(time.Millisecond, 8)
+	defer tw.Close()
+	var fired atomic.Bool
+	tm := tw.AfterFunc(20*time.Millisecond, func() {
+		fired.Store(true)
+	})
+	assert.True(t, tm.Stop())
+	assert.False(t, tm.Stop())
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, fired.Load())
+}
+
+func TestReset(t *testing.T) {
+	tw := New(time.Millisecond, 8)
+	defer tw.Close()
+	var fired atomic.Int32
+	begin := time.Now()
+	done := make(chan time.Duration, 2)
+	tm := tw.AfterFunc(10*time.Millisecond, func() {
+		fired.Add(1)
+		done <- time.Since(begin)
+	})
+	assert.True(t, tm.Reset(40*time.Millisecond))
+	assert.GreaterOrEqual(t, <-done, 40*time.Millisecond)
+	assert.False(t, tm.Reset(5*time.Millisecond))
+	<-done
+	assert.Equal(t, int32(2), fired.Load())
+}
+
+// pending count the timers linked in the wheel
+func (tw *TimeWheel) pending() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	var n int
+	for _, l := range tw.levels {
+		for i := range l.buckets {
+			b := &l.buckets[i]
+			for t := b.root.next; t != &b.root; t = t.next {
+				n++
+			}
+		}
+	}
+	return n
+}
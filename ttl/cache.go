@@ -0,0 +1,222 @@
+package ttl
+
+import (
+	"cmp"
+	"gotools/timewheel"
+	algo "gotools/treemap"
+	"iter"
+	"sync"
+	"time"
+)
+
+// EvictReason why an entry left the cache
+type EvictReason uint8
+
+const (
+	Expired EvictReason = iota
+	Deleted
+	Replaced
+)
+
+type entry[V any] struct {
+	value    V
+	ttl      time.Duration
+	expireAt time.Time
+	timer    *timewheel.Timer
+}
+
+// expired report whether the entry is past its deadline at now, entries without ttl never expire
+func (e *entry[V]) expired(now time.Time) bool {
+	return e.ttl > 0 && !now.Before(e.expireAt)
+}
+
+// Cache ordered key/value cache whose entries are expired by timers on a TimeWheel
+type Cache[K cmp.Ordered, V any] struct {
+	tw      *timewheel.TimeWheel
+	mu      sync.Mutex
+	tree    *algo.RedBlackTree[K, *entry[V]]
+	onEvict func(key K, value V, reason EvictReason)
+}
+
+// New create a cache expiring its entries on tw
+func New[K cmp.Ordered, V any](tw *timewheel.TimeWheel) *Cache[K, V] {
+	return &Cache[K, V]{tw: tw, tree: algo.New[K, *entry[V]]()}
+}
+
+// OnEvict set the callback invoked after an entry is expired, deleted or replaced, it runs without the cache lock
+func (c *Cache[K, V]) OnEvict(fn func(key K, value V, reason EvictReason)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onEvict = fn
+}
+
+// Set store value under key for ttl, a ttl not greater than zero never expires
+func (c *Cache[K, V]) Set(key K, value V, ttl time.Duration) {
+	e := &entry[V]{value: value, ttl: ttl}
+	c.mu.Lock()
+	if ttl > 0 {
+		e.expireAt = c.tw.Clock().Now().Add(ttl)
+		e.timer = c.tw.AfterFunc(ttl, func() {
+			c.expire(key, e)
+		})
+	}
+	old, replaced := c.tree.Put(key, e)
+	if replaced {
+		c.stop(old)
+	}
+	onEvict := c.onEvict
+	c.mu.Unlock()
+	if replaced && onEvict != nil {
+		onEvict(key, old.value, Replaced)
+	}
+}
+
+// Get value of key if present and not expired
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.tree.TryGet(key)
+	if !ok || e.expired(c.tw.Clock().Now()) {
+		return *new(V), false
+	}
+	return e.value, true
+}
+
+// TTL remaining time to live of key, 0 for entries that never expire
+func (c *Cache[K, V]) TTL(key K) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.tree.TryGet(key)
+	now := c.tw.Clock().Now()
+	if !ok || e.expired(now) {
+		return 0, false
+	}
+	if e.ttl <= 0 {
+		return 0, true
+	}
+	return e.expireAt.Sub(now), true
+}
+
+// Touch restart the ttl of key from now, return false if key is absent or already expired
+func (c *Cache[K, V]) Touch(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.tree.TryGet(key)
+	now := c.tw.Clock().Now()
+	if !ok || e.expired(now) {
+		return false
+	}
+	if e.ttl > 0 {
+		e.expireAt = now.Add(e.ttl)
+		e.timer.Reset(e.ttl)
+	}
+	return true
+}
+
+// Delete remove key, return its value if it was present and not expired
+func (c *Cache[K, V]) Delete(key K) (V, bool) {
+	c.mu.Lock()
+	e, ok := c.tree.TryGet(key)
+	if !ok {
+		c.mu.Unlock()
+		return *new(V), false
+	}
+	c.tree.Delete(key)
+	c.stop(e)
+	live := !e.expired(c.tw.Clock().Now())
+	onEvict := c.onEvict
+	c.mu.Unlock()
+	if onEvict != nil {
+		if live {
+			onEvict(key, e.value, Deleted)
+		} else {
+			onEvict(key, e.value, Expired)
+		}
+	}
+	if !live {
+		return *new(V), false
+	}
+	return e.value, true
+}
+
+// Len count of entries, including expired ones whose eviction is still pending
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tree.Len()
+}
+
+// Keys live keys in ascending order
+func (c *Cache[K, V]) Keys() []K {
+	ks := make([]K, 0)
+	for k := range c.Iter() {
+		ks = append(ks, k)
+	}
+	return ks
+}
+
+// Iter live entries in ascending key order, taken as a copy so yield may call back into the cache
+func (c *Cache[K, V]) Iter() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c.mu.Lock()
+		items := c.collect(c.tree.Iter())
+		c.mu.Unlock()
+		for _, it := range items {
+			if !yield(it.key, it.value) {
+				return
+			}
+		}
+	}
+}
+
+// IterRange live entries with beg <= key < end in ascending key order
+func (c *Cache[K, V]) IterRange(beg, end K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c.mu.Lock()
+		items := c.collect(c.tree.IterRange(beg, end))
+		c.mu.Unlock()
+		for _, it := range items {
+			if !yield(it.key, it.value) {
+				return
+			}
+		}
+	}
+}
+
+type item[K, V any] struct {
+	key   K
+	value V
+}
+
+func (c *Cache[K, V]) collect(seq iter.Seq2[K, *entry[V]]) []item[K, V] {
+	now := c.tw.Clock().Now()
+	items := make([]item[K, V], 0)
+	for k, e := range seq {
+		if !e.expired(now) {
+			items = append(items, item[K, V]{key: k, value: e.value})
+		}
+	}
+	return items
+}
+
+func (c *Cache[K, V]) stop(e *entry[V]) {
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+}
+
+// expire evict e from key unless it was replaced or touched since its timer fired
+func (c *Cache[K, V]) expire(key K, e *entry[V]) {
+	c.mu.Lock()
+	cur, ok := c.tree.TryGet(key)
+	if !ok || cur != e || !e.expired(c.tw.Clock().Now()) {
+		c.mu.Unlock()
+		return
+	}
+	c.tree.Delete(key)
+	onEvict := c.onEvict
+	c.mu.Unlock()
+	if onEvict != nil {
+		onEvict(key, e.value, Expired)
+	}
+}
@@ -0,0 +1,93 @@
+package ttl
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gotools/timewheel"
+	"testing"
+	"time"
+)
+
+type eviction struct {
+	key    string
+	value  int
+	reason EvictReason
+}
+
+func newCache() (*Cache[string, int], *timewheel.FakeClock, *[]eviction) {
+	clock := timewheel.NewFakeClock(time.Unix(0, 0))
+	tw := timewheel.New(time.Millisecond, 16, timewheel.WithClock(clock))
+	c := New[string, int](tw)
+	evicted := make([]eviction, 0)
+	c.OnEvict(func(key string, value int, reason EvictReason) {
+		evicted = append(evicted, eviction{key, value, reason})
+	})
+	return c, clock, &evicted
+}
+
+func TestExpire(t *testing.T) {
+	c, clock, evicted := newCache()
+	c.Set("b", 2, 20*time.Millisecond)
+	c.Set("a", 1, 10*time.Millisecond)
+	c.Set("c", 3, 0)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, []string{"a", "b", "c"}, c.Keys())
+	clock.Advance(10 * time.Millisecond)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"b", "c"}, c.Keys())
+	clock.Advance(time.Second)
+	assert.Equal(t, []string{"c"}, c.Keys())
+	assert.Equal(t, []eviction{{"a", 1, Expired}, {"b", 2, Expired}}, *evicted)
+	d, ok := c.TTL("c")
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), d)
+}
+
+func TestTouch(t *testing.T) {
+	c, clock, evicted := newCache()
+	c.Set("a", 1, 10*time.Millisecond)
+	for range 5 {
+		clock.Advance(8 * time.Millisecond)
+		assert.True(t, c.Touch("a"))
+	}
+	d, _ := c.TTL("a")
+	assert.Equal(t, 10*time.Millisecond, d)
+	clock.Advance(10 * time.Millisecond)
+	assert.False(t, c.Touch("a"))
+	assert.Equal(t, []eviction{{"a", 1, Expired}}, *evicted)
+}
+
+func TestReplaceDelete(t *testing.T) {
+	c, clock, evicted := newCache()
+	c.Set("a", 1, 10*time.Millisecond)
+	c.Set("a", 2, 30*time.Millisecond)
+	clock.Advance(20 * time.Millisecond)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	v, ok = c.Delete("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	_, ok = c.Delete("a")
+	assert.False(t, ok)
+	clock.Advance(time.Second)
+	assert.Equal(t, []eviction{{"a", 1, Replaced}, {"a", 2, Deleted}}, *evicted)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestIterRange(t *testing.T) {
+	c, clock, _ := newCache()
+	for i, k := range []string{"e", "a", "d", "b", "c"} {
+		c.Set(k, i, time.Duration(i+1)*time.Millisecond)
+	}
+	clock.Advance(time.Millisecond)
+	keys := make([]string, 0)
+	for k := range c.IterRange("b", "e") {
+		keys = append(keys, k)
+		// callbacks into the cache while iterating must not deadlock
+		c.Touch(k)
+	}
+	assert.Equal(t, []string{"b", "c", "d"}, keys)
+}